@@ -0,0 +1,233 @@
+package forthic
+
+import (
+	"container/list"
+	"errors"
+)
+
+// DefaultCompileCacheSize is the number of compiled code strings an
+// Interpreter keeps by default
+const DefaultCompileCacheSize = 256
+
+// ============================================================================
+// Compile
+// ============================================================================
+
+// Compile compiles Forthic code into a DefinitionWord without executing it
+//
+// Words are resolved once, at compile time, so the result can be executed
+// many times without re-tokenizing the code. This is what higher-order words
+// like MAP and SELECT use to run their code for each element.
+//
+// Code that defines words (":", "@:") has compile-time side effects, so it is
+// compiled into a word that simply runs the code each time it is executed.
+// So is code that uses a word that isn't defined yet, since the code may
+// create it (with VARIABLES, USE-MODULES or INTERPRET, say) before using it.
+//
+// Compiled words are cached by code string. Cached entries are discarded when
+// words or variables are added to any module on the module stack.
+//
+// As with Run, code from a string literal in running code reports locations
+// relative to the string literal.
+func (i *Interpreter) Compile(code string) (*DefinitionWord, error) {
+	reference := i.nestedCodeLocation(code)
+	if word := i.compileCache.get(code, reference, i.moduleStack); word != nil {
+		return word, nil
+	}
+
+	word, err := i.compile(code, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	i.compileCache.put(code, reference, i.moduleStack, word)
+	return word, nil
+}
+
+// SetCompileCacheSize sets the maximum number of compiled code strings cached
+// A size of 0 disables caching.
+func (i *Interpreter) SetCompileCacheSize(size int) {
+	i.compileCache = newCompileCache(size)
+}
+
+// compile compiles code into a DefinitionWord, leaving interpreter state untouched
+func (i *Interpreter) compile(code string, reference *CodeLocation) (word *DefinitionWord, err error) {
+	state := i.saveRunState()
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicToError(r, nil, i.CurrentLocation())
+		}
+		i.restoreRunState(state)
+	}()
+
+	tokenizer := NewTokenizer(code, reference, false)
+	i.tokenizerStack = append(i.tokenizerStack, tokenizer)
+
+	i.curDefinition = NewDefinitionWord("<compiled>", nil)
+	i.isCompiling = true
+	i.isMemoDefinition = false
+
+	for {
+		token, err := tokenizer.NextToken()
+		if err != nil {
+			return nil, err
+		}
+
+		switch token.Type {
+		case TOKEN_EOS:
+			return i.curDefinition, nil
+		case TOKEN_START_DEF, TOKEN_START_MEMO, TOKEN_END_DEF:
+			return NewDefinitionWord("<compiled>", []Word{NewRunCodeWord(code, reference)}), nil
+		}
+
+		if err := i.handleToken(token); err != nil {
+			var unknown *UnknownWordError
+			if errors.As(err, &unknown) {
+				return NewDefinitionWord("<compiled>", []Word{NewRunCodeWord(code, reference)}), nil
+			}
+			return nil, err
+		}
+		i.previousToken = token
+	}
+}
+
+// RunCodeWord - Word that runs a string of Forthic code each time it executes
+//
+// Used by Compile for code that can't be compiled ahead of time.
+type RunCodeWord struct {
+	*BaseWord
+	code      string
+	reference *CodeLocation
+}
+
+// NewRunCodeWord creates a new RunCodeWord
+// reference is the location of the code's first character, or nil.
+func NewRunCodeWord(code string, reference *CodeLocation) *RunCodeWord {
+	return &RunCodeWord{
+		BaseWord:  NewBaseWord("<run>"),
+		code:      code,
+		reference: reference,
+	}
+}
+
+func (w *RunCodeWord) Execute(interp *Interpreter) error {
+	return interp.run(w.code, w.reference)
+}
+
+// ============================================================================
+// Compile Cache
+// ============================================================================
+
+// compileCache is an LRU cache of compiled code keyed by code string
+//
+// Each entry remembers the module stack it was compiled against, along with
+// the version of each module, so that entries are only reused when word
+// resolution would produce the same result. Entries also remember the
+// location the code was compiled at, so that reported locations stay right.
+type compileCache struct {
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List // front = most recently used
+}
+
+type compileCacheEntry struct {
+	code      string
+	reference *CodeLocation
+	modules   []*Module
+	versions  []uint64
+	word      *DefinitionWord
+}
+
+func newCompileCache(capacity int) *compileCache {
+	return &compileCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// get returns the cached word for code, or nil if missing or stale
+func (c *compileCache) get(code string, reference *CodeLocation, moduleStack []*Module) *DefinitionWord {
+	elem, ok := c.entries[code]
+	if !ok {
+		return nil
+	}
+
+	entry := elem.Value.(*compileCacheEntry)
+	if !entry.matches(reference, moduleStack) {
+		c.order.Remove(elem)
+		delete(c.entries, code)
+		return nil
+	}
+
+	c.order.MoveToFront(elem)
+	return entry.word
+}
+
+// put caches a compiled word, evicting the least recently used entry if full
+func (c *compileCache) put(code string, reference *CodeLocation, moduleStack []*Module, word *DefinitionWord) {
+	if c.capacity <= 0 {
+		return
+	}
+
+	entry := &compileCacheEntry{
+		code:      code,
+		reference: reference,
+		modules:   make([]*Module, len(moduleStack)),
+		versions:  make([]uint64, len(moduleStack)),
+		word:      word,
+	}
+	for j, module := range moduleStack {
+		entry.modules[j] = module
+		entry.versions[j] = module.version
+	}
+
+	if elem, ok := c.entries[code]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[code] = c.order.PushFront(entry)
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*compileCacheEntry).code)
+	}
+}
+
+// clear removes all entries
+func (c *compileCache) clear() {
+	c.entries = make(map[string]*list.Element)
+	c.order.Init()
+}
+
+// len returns the number of cached entries
+func (c *compileCache) len() int {
+	return c.order.Len()
+}
+
+// matches reports whether the entry was compiled at this location, against
+// this module stack, and no module on it has changed since
+func (e *compileCacheEntry) matches(reference *CodeLocation, moduleStack []*Module) bool {
+	if !sameLocation(reference, e.reference) {
+		return false
+	}
+	if len(moduleStack) != len(e.modules) {
+		return false
+	}
+	for j, module := range moduleStack {
+		if module != e.modules[j] || module.version != e.versions[j] {
+			return false
+		}
+	}
+	return true
+}
+
+// sameLocation reports whether two locations, either of which may be nil, are equal
+func sameLocation(a *CodeLocation, b *CodeLocation) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
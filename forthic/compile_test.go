@@ -0,0 +1,130 @@
+package forthic
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompile_ExecutesLikeRun(t *testing.T) {
+	interp := NewInterpreter()
+	err := interp.Run(`: PUSH_42 42 ;`)
+	assert.NoError(t, err)
+
+	word, err := interp.Compile(`PUSH_42 "hello" [1 2]`)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, interp.GetStack().Length())
+
+	for j := 0; j < 2; j++ {
+		assert.NoError(t, word.Execute(interp))
+	}
+	assert.Equal(t, 6, interp.GetStack().Length())
+	assert.Equal(t, []interface{}{int64(1), int64(2)}, interp.StackPop())
+	assert.Equal(t, "hello", interp.StackPop())
+	assert.Equal(t, int64(42), interp.StackPop())
+}
+
+func TestCompile_LeavesStateUntouched(t *testing.T) {
+	interp := NewInterpreter()
+	_, err := interp.Compile(`{mymodule 1 }`)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(interp.moduleStack))
+	assert.Equal(t, 0, len(interp.tokenizerStack))
+	assert.False(t, interp.isCompiling)
+}
+
+func TestCompile_UnknownWord(t *testing.T) {
+	interp := NewInterpreter()
+	word, err := interp.Compile(`1 UNKNOWN_WORD`)
+	assert.NoError(t, err)
+	assert.False(t, interp.isCompiling)
+
+	// The word is looked up when the code runs, since the code (or code run
+	// before it) may define it
+	err = word.Execute(interp)
+	assert.True(t, errors.Is(err, ErrUnknownWord))
+
+	interp.GetStack().Clear()
+	assert.NoError(t, interp.Run(`: UNKNOWN_WORD 2 ;`))
+	assert.NoError(t, word.Execute(interp))
+	assert.Equal(t, []interface{}{int64(1), int64(2)}, interp.GetStack().Items())
+}
+
+func TestCompile_DefinitionsRunEachTime(t *testing.T) {
+	interp := NewInterpreter()
+	word, err := interp.Compile(`: PUSH_7 7 ; PUSH_7`)
+	assert.NoError(t, err)
+
+	// Nothing is defined until the word executes
+	assert.Nil(t, interp.CurModule().FindDictionaryWord("PUSH_7"))
+
+	assert.NoError(t, word.Execute(interp))
+	assert.Equal(t, int64(7), interp.StackPop())
+}
+
+func TestCompile_CacheHit(t *testing.T) {
+	interp := NewInterpreter()
+	word1, err := interp.Compile(`1 2`)
+	assert.NoError(t, err)
+	word2, err := interp.Compile(`1 2`)
+	assert.NoError(t, err)
+	assert.Same(t, word1, word2)
+}
+
+func TestCompile_CacheInvalidatedByNewDefinition(t *testing.T) {
+	interp := NewInterpreter()
+	assert.NoError(t, interp.Run(`: VALUE 1 ;`))
+
+	word, err := interp.Compile(`VALUE`)
+	assert.NoError(t, err)
+	assert.NoError(t, word.Execute(interp))
+	assert.Equal(t, int64(1), interp.StackPop())
+
+	// Redefining VALUE must not reuse the stale compiled word
+	assert.NoError(t, interp.Run(`: VALUE 2 ;`))
+	word, err = interp.Compile(`VALUE`)
+	assert.NoError(t, err)
+	assert.NoError(t, word.Execute(interp))
+	assert.Equal(t, int64(2), interp.StackPop())
+}
+
+func TestCompile_CacheDependsOnModuleStack(t *testing.T) {
+	interp := NewInterpreter()
+	assert.NoError(t, interp.Run(`: VALUE 1 ; {mymodule : VALUE 2 ; }`))
+
+	word, err := interp.Compile(`VALUE`)
+	assert.NoError(t, err)
+	assert.NoError(t, word.Execute(interp))
+	assert.Equal(t, int64(1), interp.StackPop())
+
+	assert.NoError(t, interp.Run(`{mymodule`))
+	word, err = interp.Compile(`VALUE`)
+	assert.NoError(t, err)
+	assert.NoError(t, word.Execute(interp))
+	assert.Equal(t, int64(2), interp.StackPop())
+}
+
+func TestCompile_CacheEvictsLeastRecentlyUsed(t *testing.T) {
+	interp := NewInterpreter()
+	interp.SetCompileCacheSize(2)
+
+	word1, _ := interp.Compile(`1`)
+	interp.Compile(`2`)
+	interp.Compile(`1`) // 1 is now most recently used
+	interp.Compile(`3`) // evicts 2
+	assert.Equal(t, 2, interp.compileCache.len())
+
+	again, _ := interp.Compile(`1`)
+	assert.Same(t, word1, again)
+	assert.Nil(t, interp.compileCache.get(`2`, nil, interp.moduleStack))
+}
+
+func TestCompile_CacheDisabled(t *testing.T) {
+	interp := NewInterpreter()
+	interp.SetCompileCacheSize(0)
+
+	word1, _ := interp.Compile(`1`)
+	word2, _ := interp.Compile(`1`)
+	assert.NotSame(t, word1, word2)
+}
@@ -0,0 +1,97 @@
+package forthic
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunContext_Completes(t *testing.T) {
+	interp := NewInterpreter()
+	err := interp.RunContext(context.Background(), `: PUSH_42 42 ; PUSH_42`)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), interp.StackPop())
+}
+
+func TestRunContext_Cancelled(t *testing.T) {
+	interp := NewInterpreter()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := interp.RunContext(ctx, `1 2 3`)
+	var cancelled *CancelledError
+	assert.True(t, errors.As(err, &cancelled))
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.NotNil(t, cancelled.Location)
+	assert.Equal(t, 0, interp.GetStack().Length())
+}
+
+func TestRunContext_CancelledInsideDefinition(t *testing.T) {
+	interp := NewInterpreter()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	module := NewModule("test")
+	module.AddModuleWord("CANCEL", func(interp *Interpreter) error {
+		cancel()
+		return nil
+	})
+	interp.ImportModule(module, "")
+
+	err := interp.RunContext(ctx, `: WORK 1 CANCEL 2 ; WORK`)
+	var cancelled *CancelledError
+	assert.True(t, errors.As(err, &cancelled))
+	assert.Equal(t, 1, interp.GetStack().Length())
+	assert.Equal(t, 17, cancelled.Location.Column)
+}
+
+func TestRunContext_DeadlineExceeded(t *testing.T) {
+	interp := NewInterpreter()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+
+	err := interp.RunContext(ctx, `1`)
+	var deadline *DeadlineExceededError
+	assert.True(t, errors.As(err, &deadline))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestRunContext_HandlersDoNotSwallowCancellation(t *testing.T) {
+	interp := NewInterpreter()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	word := NewModuleWord("CHECK", func(interp *Interpreter) error {
+		return interp.CheckContext()
+	})
+	word.AddErrorHandler(func(err error, w Word, i *Interpreter) error {
+		return nil
+	})
+
+	interp.ctx = ctx
+	err := word.Execute(interp)
+	var cancelled *CancelledError
+	assert.True(t, errors.As(err, &cancelled))
+}
+
+func TestRunContext_ModuleWordReadsContext(t *testing.T) {
+	type key struct{}
+	interp := NewInterpreter()
+
+	module := NewModule("test")
+	module.AddModuleWord("USER", func(interp *Interpreter) error {
+		interp.StackPush(interp.Context().Value(key{}))
+		return nil
+	})
+	interp.ImportModule(module, "")
+
+	ctx := context.WithValue(context.Background(), key{}, "alice")
+	assert.NoError(t, interp.RunContext(ctx, `USER`))
+	assert.Equal(t, "alice", interp.StackPop())
+
+	// Context is restored once RunContext returns
+	assert.Equal(t, context.Background(), interp.Context())
+}
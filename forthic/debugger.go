@@ -0,0 +1,269 @@
+package forthic
+
+import (
+	"sync"
+)
+
+// Pause reasons reported in PauseEvent.Reason
+const (
+	PAUSE_BREAKPOINT = "breakpoint"
+	PAUSE_STEP       = "step"
+	PAUSE_REQUESTED  = "pause"
+)
+
+// DebugFrame - A word whose execution is in progress
+type DebugFrame struct {
+	Name     string
+	Location *CodeLocation
+}
+
+// PauseEvent - Snapshot of interpreter state when execution pauses
+//
+// Frames are ordered outermost first; the last frame is the word about to
+// execute. Stack is ordered bottom to top. Variables maps each module on the
+// module stack (by name, "" for the app module) to its variable values.
+type PauseEvent struct {
+	Reason      string
+	Word        Word
+	Location    *CodeLocation
+	Frames      []DebugFrame
+	Stack       []interface{}
+	ModuleStack []string
+	Variables   map[string]map[string]interface{}
+}
+
+type stepMode int
+
+const (
+	stepNone stepMode = iota
+	stepInto
+	stepOver
+	stepOut
+)
+
+type lineKey struct {
+	file string
+	line int
+}
+
+// Debugger - Pauses a running Interpreter at breakpoints and steps through code
+//
+// The interpreter must run on its own goroutine. When execution pauses, a
+// PauseEvent is sent on the Paused channel and the interpreter blocks until
+// the controlling goroutine calls Continue, StepInto, StepOver or StepOut.
+//
+//	dbg := forthic.NewDebugger(interp)
+//	dbg.BreakOnWord("MY-WORD")
+//	go func() { done <- interp.Run(code) }()
+//	event := <-dbg.Paused()
+//	fmt.Println(event.Frames, event.Stack)
+//	dbg.StepOver()
+//
+// Breakpoints may be set from any goroutine at any time.
+type Debugger struct {
+	BaseObserver
+	interp *Interpreter
+
+	mu              sync.Mutex
+	wordBreakpoints map[string]bool
+	lineBreakpoints map[lineKey]bool
+	pauseRequested  bool
+
+	// Only touched on the interpreter's goroutine
+	frames    []DebugFrame
+	mode      stepMode
+	stepDepth int
+	lastLine  lineKey
+
+	paused   chan *PauseEvent
+	commands chan stepMode
+}
+
+// NewDebugger creates a Debugger and attaches it to interp
+func NewDebugger(interp *Interpreter) *Debugger {
+	d := &Debugger{
+		interp:          interp,
+		wordBreakpoints: make(map[string]bool),
+		lineBreakpoints: make(map[lineKey]bool),
+		frames:          make([]DebugFrame, 0),
+		paused:          make(chan *PauseEvent),
+		commands:        make(chan stepMode),
+	}
+	interp.AddObserver(d)
+	return d
+}
+
+// Detach removes the debugger from its interpreter
+// Call this only while the interpreter is not paused.
+func (d *Debugger) Detach() {
+	d.interp.RemoveObserver(d)
+}
+
+// Paused returns the channel that receives an event each time execution pauses
+func (d *Debugger) Paused() <-chan *PauseEvent {
+	return d.paused
+}
+
+// ============================================================================
+// Breakpoints
+// ============================================================================
+
+// BreakOnWord pauses before every execution of the named word
+func (d *Debugger) BreakOnWord(name string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.wordBreakpoints[name] = true
+}
+
+// ClearWordBreakpoint removes a breakpoint set by BreakOnWord
+func (d *Debugger) ClearWordBreakpoint(name string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.wordBreakpoints, name)
+}
+
+// BreakAt pauses when execution reaches the given file and line
+// Use "" for code run without a file name.
+func (d *Debugger) BreakAt(file string, line int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.lineBreakpoints[lineKey{file, line}] = true
+}
+
+// ClearBreakpointAt removes a breakpoint set by BreakAt
+func (d *Debugger) ClearBreakpointAt(file string, line int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.lineBreakpoints, lineKey{file, line})
+}
+
+// Pause requests a pause before the next word executes
+func (d *Debugger) Pause() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.pauseRequested = true
+}
+
+// ============================================================================
+// Resuming
+// ============================================================================
+
+// Continue resumes execution until the next breakpoint
+func (d *Debugger) Continue() {
+	d.commands <- stepNone
+}
+
+// StepInto resumes execution and pauses before the next word, at any depth
+func (d *Debugger) StepInto() {
+	d.commands <- stepInto
+}
+
+// StepOver resumes execution and pauses before the next word at the same
+// depth or shallower, without stopping inside the paused word
+func (d *Debugger) StepOver() {
+	d.commands <- stepOver
+}
+
+// StepOut resumes execution and pauses once the word containing the paused
+// word has finished
+func (d *Debugger) StepOut() {
+	d.commands <- stepOut
+}
+
+// ============================================================================
+// Observer
+// ============================================================================
+
+func (d *Debugger) BeforeWord(word Word, location *CodeLocation, stack StackReader) {
+	depth := len(d.frames)
+	d.frames = append(d.frames, DebugFrame{Name: word.GetName(), Location: location})
+
+	reason := d.pauseReason(word, location, depth)
+	if location != nil {
+		d.lastLine = lineKey{location.File, location.Line}
+	}
+	if reason == "" {
+		return
+	}
+
+	d.paused <- d.newPauseEvent(reason, word, location)
+	d.mode = <-d.commands
+	d.stepDepth = depth
+}
+
+func (d *Debugger) AfterWord(word Word, location *CodeLocation, stack StackReader) {
+	d.popFrame()
+}
+
+func (d *Debugger) OnError(word Word, location *CodeLocation, stack StackReader, err error) {
+	d.popFrame()
+}
+
+func (d *Debugger) popFrame() {
+	if len(d.frames) > 0 {
+		d.frames = d.frames[:len(d.frames)-1]
+	}
+}
+
+// pauseReason returns why execution should pause before word, or "" to continue
+func (d *Debugger) pauseReason(word Word, location *CodeLocation, depth int) string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.pauseRequested {
+		d.pauseRequested = false
+		return PAUSE_REQUESTED
+	}
+
+	switch d.mode {
+	case stepInto:
+		return PAUSE_STEP
+	case stepOver:
+		if depth <= d.stepDepth {
+			return PAUSE_STEP
+		}
+	case stepOut:
+		if depth < d.stepDepth {
+			return PAUSE_STEP
+		}
+	}
+
+	if d.wordBreakpoints[word.GetName()] {
+		return PAUSE_BREAKPOINT
+	}
+
+	// Line breakpoints fire once when execution arrives at the line
+	if location != nil {
+		key := lineKey{location.File, location.Line}
+		if d.lineBreakpoints[key] && key != d.lastLine {
+			return PAUSE_BREAKPOINT
+		}
+	}
+	return ""
+}
+
+// newPauseEvent snapshots the interpreter state
+func (d *Debugger) newPauseEvent(reason string, word Word, location *CodeLocation) *PauseEvent {
+	frames := make([]DebugFrame, len(d.frames))
+	copy(frames, d.frames)
+
+	event := &PauseEvent{
+		Reason:      reason,
+		Word:        word,
+		Location:    location,
+		Frames:      frames,
+		Stack:       d.interp.GetStack().Items(),
+		ModuleStack: make([]string, 0),
+		Variables:   make(map[string]map[string]interface{}),
+	}
+
+	for _, module := range d.interp.ModuleStack() {
+		event.ModuleStack = append(event.ModuleStack, module.GetName())
+		values := make(map[string]interface{})
+		for name, variable := range module.Variables() {
+			values[name] = d.interp.resolveVariable(variable).GetValue()
+		}
+		event.Variables[module.GetName()] = values
+	}
+	return event
+}
@@ -0,0 +1,210 @@
+package forthic
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runAsync runs code on a new goroutine, returning a channel for the result
+func runAsync(interp *Interpreter, code string) chan error {
+	done := make(chan error, 1)
+	go func() {
+		done <- interp.Run(code)
+	}()
+	return done
+}
+
+func nextPause(t *testing.T, dbg *Debugger) *PauseEvent {
+	select {
+	case event := <-dbg.Paused():
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for pause")
+		return nil
+	}
+}
+
+func waitDone(t *testing.T, done chan error) error {
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for run to finish")
+		return nil
+	}
+}
+
+func frameNames(event *PauseEvent) []string {
+	names := make([]string, len(event.Frames))
+	for j, frame := range event.Frames {
+		names[j] = frame.Name
+	}
+	return names
+}
+
+func TestDebugger_WordBreakpoint(t *testing.T) {
+	interp := NewInterpreter()
+	require.NoError(t, interp.Run(`: INNER 2 3 ; : OUTER 1 INNER ;`))
+	dbg := NewDebugger(interp)
+	dbg.BreakOnWord("INNER")
+
+	done := runAsync(interp, `OUTER`)
+	event := nextPause(t, dbg)
+	assert.Equal(t, PAUSE_BREAKPOINT, event.Reason)
+	assert.Equal(t, "INNER", event.Word.GetName())
+	assert.Equal(t, []string{"OUTER", "INNER"}, frameNames(event))
+	assert.Equal(t, []interface{}{int64(1)}, event.Stack)
+	assert.Equal(t, []string{""}, event.ModuleStack)
+
+	dbg.Continue()
+	assert.NoError(t, waitDone(t, done))
+	assert.Equal(t, 3, interp.GetStack().Length())
+}
+
+func TestDebugger_LineBreakpoint(t *testing.T) {
+	interp := NewInterpreter()
+	dbg := NewDebugger(interp)
+	dbg.BreakAt("", 2)
+
+	done := runAsync(interp, "1\n2 3\n4")
+	event := nextPause(t, dbg)
+	assert.Equal(t, "2", event.Word.GetName())
+	assert.Equal(t, 2, event.Location.Line)
+
+	// Continuing does not stop again on the same line
+	dbg.Continue()
+	assert.NoError(t, waitDone(t, done))
+}
+
+func TestDebugger_Stepping(t *testing.T) {
+	interp := NewInterpreter()
+	require.NoError(t, interp.Run(`: INNER 2 3 ; : OUTER 1 INNER 4 ;`))
+	dbg := NewDebugger(interp)
+	dbg.BreakOnWord("OUTER")
+
+	done := runAsync(interp, `OUTER 5`)
+	event := nextPause(t, dbg)
+	assert.Equal(t, "OUTER", event.Word.GetName())
+
+	dbg.StepInto()
+	event = nextPause(t, dbg)
+	assert.Equal(t, "1", event.Word.GetName())
+
+	dbg.StepOver()
+	event = nextPause(t, dbg)
+	assert.Equal(t, "INNER", event.Word.GetName())
+
+	// Stepping over INNER skips 2 and 3
+	dbg.StepOver()
+	event = nextPause(t, dbg)
+	assert.Equal(t, "4", event.Word.GetName())
+	assert.Equal(t, []interface{}{int64(1), int64(2), int64(3)}, event.Stack)
+
+	dbg.StepInto()
+	event = nextPause(t, dbg)
+	assert.Equal(t, "5", event.Word.GetName())
+	assert.Equal(t, []string{"5"}, frameNames(event))
+
+	dbg.Continue()
+	assert.NoError(t, waitDone(t, done))
+}
+
+func TestDebugger_StepOut(t *testing.T) {
+	interp := NewInterpreter()
+	require.NoError(t, interp.Run(`: INNER 2 3 ; : OUTER 1 INNER 4 ;`))
+	dbg := NewDebugger(interp)
+	dbg.BreakOnWord("2")
+
+	done := runAsync(interp, `OUTER`)
+	event := nextPause(t, dbg)
+	assert.Equal(t, []string{"OUTER", "INNER", "2"}, frameNames(event))
+
+	dbg.StepOut()
+	event = nextPause(t, dbg)
+	assert.Equal(t, "4", event.Word.GetName())
+	assert.Equal(t, []string{"OUTER", "4"}, frameNames(event))
+
+	dbg.Continue()
+	assert.NoError(t, waitDone(t, done))
+}
+
+func TestDebugger_StepIntoCodeString(t *testing.T) {
+	interp := NewInterpreter(newInterpretModule())
+	require.NoError(t, interp.RunSource("main.forthic", ": OUTER\n  \"1 2\" INTERPRET 3 ;"))
+	dbg := NewDebugger(interp)
+	dbg.BreakOnWord("INTERPRET")
+
+	done := runAsync(interp, `OUTER`)
+	event := nextPause(t, dbg)
+	assert.Equal(t, []string{"OUTER", "INTERPRET"}, frameNames(event))
+
+	// Stepping into INTERPRET stops at the first word of its code string
+	dbg.StepInto()
+	event = nextPause(t, dbg)
+	assert.Equal(t, "1", event.Word.GetName())
+	assert.Equal(t, []string{"OUTER", "INTERPRET", "1"}, frameNames(event))
+	require.NotNil(t, event.Location)
+	assert.Equal(t, "main.forthic", event.Location.File)
+	assert.Equal(t, 2, event.Location.Line)
+	assert.Equal(t, 4, event.Location.Column)
+
+	dbg.StepOut()
+	event = nextPause(t, dbg)
+	assert.Equal(t, "3", event.Word.GetName())
+	assert.Equal(t, []string{"OUTER", "3"}, frameNames(event))
+	assert.Equal(t, []interface{}{int64(1), int64(2)}, event.Stack)
+
+	dbg.Continue()
+	assert.NoError(t, waitDone(t, done))
+}
+
+func TestDebugger_PauseAndVariables(t *testing.T) {
+	interp := NewInterpreter()
+	interp.GetAppModule().AddVariable("x", int64(7))
+	dbg := NewDebugger(interp)
+	dbg.Pause()
+
+	done := runAsync(interp, `1`)
+	event := nextPause(t, dbg)
+	assert.Equal(t, PAUSE_REQUESTED, event.Reason)
+	assert.Equal(t, int64(7), event.Variables[""]["x"])
+
+	dbg.Continue()
+	assert.NoError(t, waitDone(t, done))
+
+	dbg.Detach()
+	assert.NoError(t, interp.Run(`1`))
+}
+
+func TestDebugger_ForkVariables(t *testing.T) {
+	interp := NewInterpreter()
+	settings := NewModule("settings")
+	settings.AddVariable("x", int64(1))
+	require.NoError(t, interp.RegisterModule(settings))
+	// Pushes the module it was created with, which a fork shares
+	interp.GetAppModule().AddModuleWord("IN-SETTINGS", func(interp *Interpreter) error {
+		interp.ModuleStackPush(settings)
+		return nil
+	})
+
+	fork := interp.Fork()
+	forkSettings, err := fork.FindModule("settings")
+	require.NoError(t, err)
+	forkSettings.GetVariable("x").SetValue(int64(2))
+
+	dbg := NewDebugger(fork)
+	dbg.BreakOnWord("NOP")
+	fork.GetAppModule().AddModuleWord("NOP", func(interp *Interpreter) error { return nil })
+
+	done := runAsync(fork, `IN-SETTINGS NOP`)
+	event := nextPause(t, dbg)
+	assert.Equal(t, []string{"", "settings"}, event.ModuleStack)
+	assert.Equal(t, int64(2), event.Variables["settings"]["x"])
+
+	dbg.Continue()
+	assert.NoError(t, waitDone(t, done))
+	assert.Equal(t, int64(1), settings.GetVariable("x").GetValue())
+}
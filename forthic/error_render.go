@@ -0,0 +1,262 @@
+package forthic
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultRenderContextLines is the number of source lines shown before and
+// after the failing line by default
+const DefaultRenderContextLines = 2
+
+// maxSuggestions bounds the number of did-you-mean suggestions
+const maxSuggestions = 3
+
+// ANSI escape sequences used when rendering in color
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiRed   = "\x1b[31m"
+	ansiBlue  = "\x1b[34m"
+	ansiCyan  = "\x1b[36m"
+)
+
+// RenderOptions - How RenderError formats an error
+type RenderOptions struct {
+	Color        bool // Use ANSI colors
+	ContextLines int  // Source lines shown before and after the failing line
+}
+
+// DefaultRenderOptions returns plain-text options with default context
+func DefaultRenderOptions() RenderOptions {
+	return RenderOptions{ContextLines: DefaultRenderContextLines}
+}
+
+// ============================================================================
+// RenderError
+// ============================================================================
+
+// RenderError formats err for display, showing the failing source code
+//
+// source is the code that was run. The failing line is printed with its
+// surrounding context and the failing token underlined:
+//
+//	error[FORTHIC_UNKNOWN_WORD]: Unknown word: DUPP
+//	 --> line 2, col 3
+//	  |
+//	1 | : SQUARE DUP * ;
+//	2 | 3 DUPP SQUARE
+//	  |   ^^^^
+//	  = did you mean: DUP?
+//
+// Errors that are not Forthic errors, or lack a location within source, are
+// rendered without a snippet.
+func RenderError(err error, source string, options RenderOptions) string {
+	r := &errorRenderer{options: options}
+
+	var base forthicErrorBase
+	if !errors.As(err, &base) {
+		r.header("", err.Error())
+		return r.sb.String()
+	}
+	fe := base.forthicError()
+
+	r.header(fe.Code(), fe.Message)
+
+	location := renderLocation(fe)
+	if location != nil {
+		r.location(location)
+		r.snippet(source, location)
+	}
+
+	if fe.Forthic != "" {
+		r.note("in", fe.Forthic)
+	}
+	if fe.Cause != nil {
+		r.note("caused by", fe.Cause.Error())
+	}
+
+	var unknown *UnknownWordError
+	if errors.As(err, &unknown) && len(unknown.Suggestions) > 0 {
+		r.note("did you mean", r.color(ansiCyan, strings.Join(unknown.Suggestions, ", "))+"?")
+	}
+
+	return r.sb.String()
+}
+
+// renderLocation returns the location to render: the error's own, or else
+// the innermost located frame of its stack trace
+func renderLocation(fe *ForthicError) *CodeLocation {
+	if fe.Location != nil {
+		return fe.Location
+	}
+	for _, frame := range fe.Trace {
+		if frame.Location != nil {
+			return frame.Location
+		}
+	}
+	return nil
+}
+
+type errorRenderer struct {
+	options RenderOptions
+	sb      strings.Builder
+	gutter  int
+}
+
+// color wraps text in an ANSI style when color output is enabled
+func (r *errorRenderer) color(style string, text string) string {
+	if !r.options.Color {
+		return text
+	}
+	return style + text + ansiReset
+}
+
+func (r *errorRenderer) header(code string, message string) {
+	label := "error"
+	if code != "" {
+		label = fmt.Sprintf("error[%s]", code)
+	}
+	r.sb.WriteString(r.color(ansiBold+ansiRed, label))
+	r.sb.WriteString(r.color(ansiBold, ": "+message))
+	r.sb.WriteString("\n")
+}
+
+func (r *errorRenderer) location(location *CodeLocation) {
+	r.gutter = len(fmt.Sprint(location.Line + r.options.ContextLines))
+	fmt.Fprintf(&r.sb, "%s%s %s\n", strings.Repeat(" ", r.gutter), r.color(ansiBlue, "-->"), location)
+}
+
+func (r *errorRenderer) note(label string, text string) {
+	fmt.Fprintf(&r.sb, "%s %s %s: %s\n", strings.Repeat(" ", r.gutter), r.color(ansiBlue, "="), label, text)
+}
+
+// snippet prints the failing line, its context and an underline
+func (r *errorRenderer) snippet(source string, location *CodeLocation) {
+	lines := strings.Split(source, "\n")
+	if location.Line < 1 || location.Line > len(lines) {
+		return
+	}
+
+	first := location.Line - r.options.ContextLines
+	if first < 1 {
+		first = 1
+	}
+	last := location.Line + r.options.ContextLines
+	if last > len(lines) {
+		last = len(lines)
+	}
+
+	bar := r.color(ansiBlue, "|")
+	fmt.Fprintf(&r.sb, "%s %s\n", strings.Repeat(" ", r.gutter), bar)
+	for n := first; n <= last; n++ {
+		line := strings.TrimRight(lines[n-1], "\r")
+		number := r.color(ansiBlue, fmt.Sprintf("%*d", r.gutter, n))
+		fmt.Fprintf(&r.sb, "%s %s %s\n", number, bar, line)
+		if n == location.Line {
+			fmt.Fprintf(&r.sb, "%s %s %s\n", strings.Repeat(" ", r.gutter), bar, r.underline(line, location))
+		}
+	}
+}
+
+// underline returns carets under the token at location
+// Tabs before the token are kept so the carets line up with the source.
+func (r *errorRenderer) underline(line string, location *CodeLocation) string {
+	start := location.Column - 1
+	if start < 0 {
+		start = 0
+	}
+	if start > len(line) {
+		start = len(line)
+	}
+
+	width := location.EndPos - location.StartPos
+	if width < 1 {
+		width = 1
+	}
+	if start+width > len(line) && start < len(line) {
+		width = len(line) - start
+	}
+
+	var prefix strings.Builder
+	for _, ch := range line[:start] {
+		if ch == '\t' {
+			prefix.WriteRune('\t')
+		} else {
+			prefix.WriteRune(' ')
+		}
+	}
+	return prefix.String() + r.color(ansiBold+ansiRed, strings.Repeat("^", width))
+}
+
+// ============================================================================
+// Did-You-Mean Suggestions
+// ============================================================================
+
+// suggestWords returns the word names on the module stack closest to name
+// Names are compared case-insensitively by edit distance; only close matches
+// are returned, nearest first.
+func (i *Interpreter) suggestWords(name string) []string {
+	target := strings.ToUpper(name)
+	maxDistance := len(target)/3 + 1
+
+	type candidate struct {
+		name     string
+		distance int
+	}
+	seen := make(map[string]bool)
+	candidates := make([]candidate, 0)
+	for _, module := range i.moduleStack {
+		for _, word := range module.WordNames() {
+			if seen[word] || word == name {
+				continue
+			}
+			seen[word] = true
+			distance := editDistance(target, strings.ToUpper(word))
+			if distance <= maxDistance {
+				candidates = append(candidates, candidate{word, distance})
+			}
+		}
+	}
+
+	sort.Slice(candidates, func(a, b int) bool {
+		if candidates[a].distance != candidates[b].distance {
+			return candidates[a].distance < candidates[b].distance
+		}
+		return candidates[a].name < candidates[b].name
+	})
+
+	result := make([]string, 0, maxSuggestions)
+	for _, c := range candidates {
+		if len(result) == maxSuggestions {
+			break
+		}
+		result = append(result, c.name)
+	}
+	return result
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a string, b string) int {
+	ar, br := []rune(a), []rune(b)
+	prev := make([]int, len(br)+1)
+	cur := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for x := 1; x <= len(ar); x++ {
+		cur[0] = x
+		for y := 1; y <= len(br); y++ {
+			cost := 1
+			if ar[x-1] == br[y-1] {
+				cost = 0
+			}
+			cur[y] = min(prev[y]+1, cur[y-1]+1, prev[y-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(br)]
+}
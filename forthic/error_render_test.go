@@ -0,0 +1,109 @@
+package forthic
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderError_UnknownWordSnippet(t *testing.T) {
+	interp := NewInterpreter()
+	code := ": DOUBLE DUP DUP ;\n3 DUPP DOUBLE"
+	interp.GetAppModule().AddWord(NewPushValueWord("DUP", nil))
+	err := interp.Run(code)
+	assert.Error(t, err)
+
+	expected := strings.Join([]string{
+		"error[FORTHIC_UNKNOWN_WORD]: Unknown word: DUPP",
+		" --> line 2, col 3",
+		"  |",
+		"1 | : DOUBLE DUP DUP ;",
+		"2 | 3 DUPP DOUBLE",
+		"  |   ^^^^",
+		"  = did you mean: DUP?",
+		"",
+	}, "\n")
+	assert.Equal(t, expected, RenderError(err, code, DefaultRenderOptions()))
+}
+
+func TestRenderError_ContextLines(t *testing.T) {
+	interp := NewInterpreter()
+	code := "1\n2\n3\n4\nNOPE\n6\n7\n8"
+	err := interp.Run(code)
+
+	options := DefaultRenderOptions()
+	options.ContextLines = 1
+	rendered := RenderError(err, code, options)
+	assert.Contains(t, rendered, "4 | 4\n5 | NOPE\n  | ^^^^\n6 | 6\n")
+	assert.NotContains(t, rendered, "3 | 3")
+	assert.NotContains(t, rendered, "7 | 7")
+}
+
+func TestRenderError_TabsKeepCaretAligned(t *testing.T) {
+	interp := NewInterpreter()
+	code := "\t1 NOPE"
+	err := interp.Run(code)
+
+	rendered := RenderError(err, code, DefaultRenderOptions())
+	assert.Contains(t, rendered, "1 | \t1 NOPE\n  | \t  ^^^^\n")
+}
+
+func TestRenderError_Color(t *testing.T) {
+	interp := NewInterpreter()
+	code := "NOPE"
+	err := interp.Run(code)
+
+	options := DefaultRenderOptions()
+	options.Color = true
+	rendered := RenderError(err, code, options)
+	assert.Contains(t, rendered, ansiRed)
+	assert.Contains(t, rendered, ansiReset)
+	assert.Contains(t, rendered, "NOPE")
+
+	plain := RenderError(err, code, DefaultRenderOptions())
+	assert.NotContains(t, plain, "\x1b[")
+}
+
+func TestRenderError_NonForthicError(t *testing.T) {
+	rendered := RenderError(errors.New("boom"), "", DefaultRenderOptions())
+	assert.Equal(t, "error: boom\n", rendered)
+}
+
+func TestRenderError_UsesTraceLocation(t *testing.T) {
+	interp := NewInterpreter()
+	module := NewModule("test-module", "")
+	module.AddModuleWord("FAIL", func(interp *Interpreter) error {
+		return errors.New("boom")
+	})
+	interp.ImportModule(module, "")
+
+	code := "1 2\nFAIL"
+	err := interp.Run(code)
+	rendered := RenderError(err, code, DefaultRenderOptions())
+	assert.Contains(t, rendered, "2 | FAIL\n  | ^^^^\n")
+	assert.Contains(t, rendered, "= caused by: boom")
+}
+
+func TestSuggestWords(t *testing.T) {
+	interp := NewInterpreter()
+	app := interp.GetAppModule()
+	for _, name := range []string{"SWAP", "SWAP-ALL", "OVER", "DUP", "ROT"} {
+		app.AddWord(NewPushValueWord(name, nil))
+	}
+	app.AddVariable("counter", 0)
+
+	assert.Equal(t, []string{"SWAP"}, interp.suggestWords("SAWP"))
+	assert.Equal(t, []string{"DUP"}, interp.suggestWords("dup"))
+	assert.Equal(t, []string{"counter"}, interp.suggestWords("countr"))
+	assert.Empty(t, interp.suggestWords("XYZZY"))
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("DUP", "DUP"))
+	assert.Equal(t, 1, editDistance("DUP", "DUPP"))
+	assert.Equal(t, 2, editDistance("SWAP", "SAWP"))
+	assert.Equal(t, 3, editDistance("", "ABC"))
+	assert.Equal(t, 3, editDistance("KITTEN", "SITTING"))
+}
@@ -1,7 +1,10 @@
 package forthic
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 )
 
@@ -22,12 +25,121 @@ func (l CodeLocation) String() string {
 	return fmt.Sprintf("%s:%d:%d", l.File, l.Line, l.Column)
 }
 
+// StackFrame - One entry in a Forthic stack trace
+//
+// Frames for words record the word's name and where it was called. Frames
+// added by higher-order words (MAP, SELECT, ...) also record the code string
+// being run and the element (index or key) it was run for.
+type StackFrame struct {
+	Word     string
+	Location *CodeLocation
+	Code     string
+	Item     interface{}
+}
+
+// File returns the source file of the frame's location, if known
+func (f StackFrame) File() string {
+	if f.Location == nil {
+		return ""
+	}
+	return f.Location.File
+}
+
+func (f StackFrame) String() string {
+	name := f.Word
+	if f.Code != "" {
+		name = fmt.Sprintf("%s %q", name, f.Code)
+	}
+	if f.Item != nil {
+		name = fmt.Sprintf("%s (item %v)", name, f.Item)
+	}
+
+	location := "<unknown location>"
+	if f.Location != nil {
+		location = f.Location.String()
+	}
+	return name + "\n\t" + location
+}
+
+// ============================================================================
+// Error Codes
+// ============================================================================
+
+// Stable error codes, returned by ForthicError.Code and GetErrorCode
+const (
+	CODE_ERROR                 = "FORTHIC_ERROR"
+	CODE_UNKNOWN_WORD          = "FORTHIC_UNKNOWN_WORD"
+	CODE_UNKNOWN_MODULE        = "FORTHIC_UNKNOWN_MODULE"
+	CODE_STACK_UNDERFLOW       = "FORTHIC_STACK_UNDERFLOW"
+	CODE_WORD_EXECUTION        = "FORTHIC_WORD_EXECUTION"
+	CODE_MISSING_SEMICOLON     = "FORTHIC_MISSING_SEMICOLON"
+	CODE_EXTRA_SEMICOLON       = "FORTHIC_EXTRA_SEMICOLON"
+	CODE_MODULE_ERROR          = "FORTHIC_MODULE_ERROR"
+	CODE_INTENTIONAL_STOP      = "FORTHIC_INTENTIONAL_STOP"
+	CODE_INVALID_VARIABLE_NAME = "FORTHIC_INVALID_VARIABLE_NAME"
+	CODE_CANCELLED             = "FORTHIC_CANCELLED"
+	CODE_DEADLINE_EXCEEDED     = "FORTHIC_DEADLINE_EXCEEDED"
+	CODE_RESOURCE_LIMIT        = "FORTHIC_RESOURCE_LIMIT"
+	CODE_INVALID_OPTION        = "FORTHIC_INVALID_OPTION"
+)
+
+// sentinelError is matched by errors.Is against any Forthic error with its code
+type sentinelError struct {
+	code string
+}
+
+func (s *sentinelError) Error() string {
+	return s.code
+}
+
+// Sentinel errors for use with errors.Is
+//
+//	if errors.Is(err, forthic.ErrUnknownWord) { ... }
+//
+// ErrForthic matches every Forthic error.
+var (
+	ErrForthic             error = &sentinelError{CODE_ERROR}
+	ErrUnknownWord         error = &sentinelError{CODE_UNKNOWN_WORD}
+	ErrUnknownModule       error = &sentinelError{CODE_UNKNOWN_MODULE}
+	ErrStackUnderflow      error = &sentinelError{CODE_STACK_UNDERFLOW}
+	ErrWordExecution       error = &sentinelError{CODE_WORD_EXECUTION}
+	ErrMissingSemicolon    error = &sentinelError{CODE_MISSING_SEMICOLON}
+	ErrExtraSemicolon      error = &sentinelError{CODE_EXTRA_SEMICOLON}
+	ErrModule              error = &sentinelError{CODE_MODULE_ERROR}
+	ErrIntentionalStop     error = &sentinelError{CODE_INTENTIONAL_STOP}
+	ErrInvalidVariableName error = &sentinelError{CODE_INVALID_VARIABLE_NAME}
+	ErrCancelled           error = &sentinelError{CODE_CANCELLED}
+	ErrDeadlineExceeded    error = &sentinelError{CODE_DEADLINE_EXCEEDED}
+	ErrResourceLimit       error = &sentinelError{CODE_RESOURCE_LIMIT}
+	ErrInvalidOption       error = &sentinelError{CODE_INVALID_OPTION}
+)
+
+// GetErrorCode returns the code of the outermost Forthic error in err's chain
+// Returns "" if err is not a Forthic error.
+func GetErrorCode(err error) string {
+	var base forthicErrorBase
+	if !errors.As(err, &base) {
+		return ""
+	}
+	return base.forthicError().Code()
+}
+
+// ============================================================================
+// ForthicError
+// ============================================================================
+
 // ForthicError is the base error type for all Forthic errors
+//
+// Typed errors embed *ForthicError. Their builder methods (WithLocation,
+// WithForthic, WithCause) return the typed error, so chained calls keep the
+// concrete type for errors.As.
 type ForthicError struct {
+	code     string
 	Message  string
 	Forthic  string
 	Location *CodeLocation
 	Cause    error
+	Trace    []StackFrame // Innermost frame first
 }
 
 func (e *ForthicError) Error() string {
@@ -47,16 +159,156 @@ func (e *ForthicError) Error() string {
 		parts = append(parts, fmt.Sprintf("caused by: %v", e.Cause))
 	}
 
-	return strings.Join(parts, "\n  ")
+	result := strings.Join(parts, "\n  ")
+	if len(e.Trace) > 0 {
+		result += "\n\nforthic stack trace:\n" + renderTrace(e.Trace)
+	}
+	return result
+}
+
+// traceEndFrames is the number of frames Error shows from each end of a long
+// stack trace; StackTrace returns them all
+const traceEndFrames = 20
+
+// renderTrace renders a stack trace, eliding the middle of deep traces (from
+// runaway recursion, say) as Go's panic traces do
+func renderTrace(trace []StackFrame) string {
+	var frames []string
+	if len(trace) > 2*traceEndFrames {
+		frames = make([]string, 0, 2*traceEndFrames+1)
+		for _, frame := range trace[:traceEndFrames] {
+			frames = append(frames, frame.String())
+		}
+		frames = append(frames, fmt.Sprintf("... %d frames elided ...", len(trace)-2*traceEndFrames))
+		for _, frame := range trace[len(trace)-traceEndFrames:] {
+			frames = append(frames, frame.String())
+		}
+	} else {
+		frames = make([]string, len(trace))
+		for j, frame := range trace {
+			frames[j] = frame.String()
+		}
+	}
+	return strings.Join(frames, "\n")
+}
+
+// StackTrace returns the Forthic stack trace, innermost frame first
+func (e *ForthicError) StackTrace() []StackFrame {
+	result := make([]StackFrame, len(e.Trace))
+	copy(result, e.Trace)
+	return result
+}
+
+// forthicError returns the base ForthicError
+// Typed errors embed *ForthicError, so this lets errors.As find the base of any of them.
+func (e *ForthicError) forthicError() *ForthicError {
+	return e
+}
+
+// forthicErrorBase is implemented by ForthicError and every error type embedding it
+type forthicErrorBase interface {
+	error
+	forthicError() *ForthicError
+}
+
+// AddStackFrame appends a frame to err's Forthic stack trace
+// Errors that are not Forthic errors are first wrapped in a WordExecutionError.
+func AddStackFrame(err error, frame StackFrame) error {
+	var base forthicErrorBase
+	if !errors.As(err, &base) {
+		wrapped := NewWordExecutionError(frame.Word, err)
+		wrapped.Location = frame.Location
+		base = wrapped
+		err = wrapped
+	}
+	fe := base.forthicError()
+	fe.Trace = append(fe.Trace, frame)
+	return err
+}
+
+// cloneError returns a copy of err that can gain stack frames without
+// changing err, for handing one error to several callers
+//
+// A Forthic error is copied along with its trace. Any other error that
+// wraps a Forthic error is wrapped in a new WordExecutionError, so that
+// frames are added to the wrapper rather than the wrapped error.
+func cloneError(err error, word string) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(forthicErrorBase); !ok {
+		var base forthicErrorBase
+		if !errors.As(err, &base) {
+			return err
+		}
+		return NewWordExecutionError(word, err)
+	}
+
+	// Typed errors are pointers to structs embedding *ForthicError
+	original := reflect.ValueOf(err).Elem()
+	copied := reflect.New(original.Type())
+	copied.Elem().Set(original)
+
+	var fe *ForthicError
+	if original.Type() == reflect.TypeOf(ForthicError{}) {
+		fe = copied.Interface().(*ForthicError)
+	} else {
+		field := copied.Elem().FieldByName("ForthicError")
+		base := *field.Interface().(*ForthicError)
+		fe = &base
+		field.Set(reflect.ValueOf(fe))
+	}
+	fe.Trace = append([]StackFrame(nil), fe.Trace...)
+	return copied.Interface().(error)
+}
+
+// GetStackTrace returns the Forthic stack trace carried by err, if any
+func GetStackTrace(err error) []StackFrame {
+	var base forthicErrorBase
+	if !errors.As(err, &base) {
+		return nil
+	}
+	return base.forthicError().StackTrace()
 }
 
 func (e *ForthicError) Unwrap() error {
 	return e.Cause
 }
 
+// Code returns the error's stable code (one of the CODE_* constants)
+func (e *ForthicError) Code() string {
+	if e.code == "" {
+		return CODE_ERROR
+	}
+	return e.code
+}
+
+// Is reports whether target is ErrForthic or the sentinel for this error's code
+func (e *ForthicError) Is(target error) bool {
+	sentinel, ok := target.(*sentinelError)
+	if !ok {
+		return false
+	}
+	return sentinel.code == CODE_ERROR || sentinel.code == e.Code()
+}
+
+// As lets errors.As extract the base *ForthicError from any typed error
+func (e *ForthicError) As(target interface{}) bool {
+	if p, ok := target.(**ForthicError); ok {
+		*p = e
+		return true
+	}
+	return false
+}
+
 // NewForthicError creates a new ForthicError
 func NewForthicError(message string) *ForthicError {
+	return newForthicError(CODE_ERROR, message)
+}
+
+func newForthicError(code string, message string) *ForthicError {
 	return &ForthicError{
+		code:    code,
 		Message: message,
 	}
 }
@@ -80,14 +332,17 @@ func (e *ForthicError) WithCause(cause error) *ForthicError {
 }
 
 // UnknownWordError represents an attempt to execute an unknown word
+// Suggestions lists similarly named words, nearest first, when the
+// interpreter could find any.
 type UnknownWordError struct {
 	*ForthicError
-	Word string
+	Word        string
+	Suggestions []string
 }
 
 func NewUnknownWordError(word string) *UnknownWordError {
 	return &UnknownWordError{
-		ForthicError: NewForthicError(fmt.Sprintf("Unknown word: %s", word)),
+		ForthicError: newForthicError(CODE_UNKNOWN_WORD, fmt.Sprintf("Unknown word: %s", word)),
 		Word:         word,
 	}
 }
@@ -100,19 +355,21 @@ type UnknownModuleError struct {
 
 func NewUnknownModuleError(module string) *UnknownModuleError {
 	return &UnknownModuleError{
-		ForthicError: NewForthicError(fmt.Sprintf("Unknown module: %s", module)),
+		ForthicError: newForthicError(CODE_UNKNOWN_MODULE, fmt.Sprintf("Unknown module: %s", module)),
 		Module:       module,
 	}
 }
 
 // StackUnderflowError represents an attempt to pop from an empty stack
+// Word names the word that underflowed the stack, when known.
 type StackUnderflowError struct {
 	*ForthicError
+	Word string
 }
 
 func NewStackUnderflowError() *StackUnderflowError {
 	return &StackUnderflowError{
-		ForthicError: NewForthicError("Stack underflow"),
+		ForthicError: newForthicError(CODE_STACK_UNDERFLOW, "Stack underflow"),
 	}
 }
 
@@ -124,7 +381,7 @@ type WordExecutionError struct {
 
 func NewWordExecutionError(word string, err error) *WordExecutionError {
 	return &WordExecutionError{
-		ForthicError: NewForthicError(fmt.Sprintf("Error executing word: %s", word)).WithCause(err),
+		ForthicError: newForthicError(CODE_WORD_EXECUTION, fmt.Sprintf("Error executing word: %s", word)).WithCause(err),
 		Word:         word,
 	}
 }
@@ -136,7 +393,7 @@ type MissingSemicolonError struct {
 
 func NewMissingSemicolonError() *MissingSemicolonError {
 	return &MissingSemicolonError{
-		ForthicError: NewForthicError("Missing semicolon (;) to end definition"),
+		ForthicError: newForthicError(CODE_MISSING_SEMICOLON, "Missing semicolon (;) to end definition"),
 	}
 }
 
@@ -147,7 +404,7 @@ type ExtraSemicolonError struct {
 
 func NewExtraSemicolonError() *ExtraSemicolonError {
 	return &ExtraSemicolonError{
-		ForthicError: NewForthicError("Extra semicolon (;) outside of definition"),
+		ForthicError: newForthicError(CODE_EXTRA_SEMICOLON, "Extra semicolon (;) outside of definition"),
 	}
 }
 
@@ -159,7 +416,7 @@ type ModuleError struct {
 
 func NewModuleError(module string, message string) *ModuleError {
 	return &ModuleError{
-		ForthicError: NewForthicError(fmt.Sprintf("Module error in %s: %s", module, message)),
+		ForthicError: newForthicError(CODE_MODULE_ERROR, fmt.Sprintf("Module error in %s: %s", module, message)),
 		Module:       module,
 	}
 }
@@ -171,7 +428,7 @@ type IntentionalStopError struct {
 
 func NewIntentionalStopError(message string) *IntentionalStopError {
 	return &IntentionalStopError{
-		ForthicError: NewForthicError(message),
+		ForthicError: newForthicError(CODE_INTENTIONAL_STOP, message),
 	}
 }
 
@@ -183,7 +440,286 @@ type InvalidVariableNameError struct {
 
 func NewInvalidVariableNameError(varName string) *InvalidVariableNameError {
 	return &InvalidVariableNameError{
-		ForthicError: NewForthicError(fmt.Sprintf("Invalid variable name: %s", varName)),
+		ForthicError: newForthicError(CODE_INVALID_VARIABLE_NAME, fmt.Sprintf("Invalid variable name: %s", varName)),
 		VarName:      varName,
 	}
 }
+
+// CancelledError represents execution stopped because its context was cancelled
+type CancelledError struct {
+	*ForthicError
+}
+
+func NewCancelledError(cause error) *CancelledError {
+	return &CancelledError{
+		ForthicError: newForthicError(CODE_CANCELLED, "Execution cancelled").WithCause(cause),
+	}
+}
+
+// DeadlineExceededError represents execution stopped because its context deadline passed
+type DeadlineExceededError struct {
+	*ForthicError
+}
+
+func NewDeadlineExceededError(cause error) *DeadlineExceededError {
+	return &DeadlineExceededError{
+		ForthicError: newForthicError(CODE_DEADLINE_EXCEEDED, "Execution deadline exceeded").WithCause(cause),
+	}
+}
+
+// newContextError converts a context error into a CancelledError or DeadlineExceededError
+func newContextError(err error, loc *CodeLocation) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return NewDeadlineExceededError(err).WithLocation(loc)
+	}
+	return NewCancelledError(err).WithLocation(loc)
+}
+
+// isContextError reports whether err stopped execution because of its context
+func isContextError(err error) bool {
+	var cancelled *CancelledError
+	var deadline *DeadlineExceededError
+	return errors.As(err, &cancelled) || errors.As(err, &deadline)
+}
+
+// bypassesErrorHandlers reports whether err must propagate even if a word
+// has error handlers (intentional stops, cancellation and resource limits)
+// Wrapped errors count, so a word can't hide them by adding context.
+func bypassesErrorHandlers(err error) bool {
+	var stop *IntentionalStopError
+	var limit *ResourceLimitError
+	return errors.As(err, &stop) || errors.As(err, &limit) || isContextError(err)
+}
+
+// ResourceLimitError represents execution stopped because a resource limit was exceeded
+type ResourceLimitError struct {
+	*ForthicError
+	Limit string
+	Max   int
+}
+
+func NewResourceLimitError(limit string, max int) *ResourceLimitError {
+	return &ResourceLimitError{
+		ForthicError: newForthicError(CODE_RESOURCE_LIMIT, fmt.Sprintf("Resource limit exceeded: %s (%d)", limit, max)),
+		Limit:        limit,
+		Max:          max,
+	}
+}
+
+// InvalidOptionError represents an unknown option, or an option value of the
+// wrong type, given to a word with an OptionSchema
+type InvalidOptionError struct {
+	*ForthicError
+	Word   string
+	Option string
+}
+
+func NewInvalidOptionError(word string, option string, message string) *InvalidOptionError {
+	return &InvalidOptionError{
+		ForthicError: newForthicError(CODE_INVALID_OPTION, message),
+		Word:         word,
+		Option:       option,
+	}
+}
+
+// ============================================================================
+// Typed Builder Methods
+// ============================================================================
+//
+// Each typed error overrides the ForthicError builder methods so that
+// chained calls return the typed error rather than the embedded base.
+
+func (e *UnknownWordError) WithLocation(loc *CodeLocation) *UnknownWordError {
+	e.ForthicError.WithLocation(loc)
+	return e
+}
+
+func (e *UnknownWordError) WithForthic(forthic string) *UnknownWordError {
+	e.ForthicError.WithForthic(forthic)
+	return e
+}
+
+func (e *UnknownWordError) WithCause(cause error) *UnknownWordError {
+	e.ForthicError.WithCause(cause)
+	return e
+}
+
+func (e *UnknownModuleError) WithLocation(loc *CodeLocation) *UnknownModuleError {
+	e.ForthicError.WithLocation(loc)
+	return e
+}
+
+func (e *UnknownModuleError) WithForthic(forthic string) *UnknownModuleError {
+	e.ForthicError.WithForthic(forthic)
+	return e
+}
+
+func (e *UnknownModuleError) WithCause(cause error) *UnknownModuleError {
+	e.ForthicError.WithCause(cause)
+	return e
+}
+
+func (e *StackUnderflowError) WithLocation(loc *CodeLocation) *StackUnderflowError {
+	e.ForthicError.WithLocation(loc)
+	return e
+}
+
+func (e *StackUnderflowError) WithForthic(forthic string) *StackUnderflowError {
+	e.ForthicError.WithForthic(forthic)
+	return e
+}
+
+func (e *StackUnderflowError) WithCause(cause error) *StackUnderflowError {
+	e.ForthicError.WithCause(cause)
+	return e
+}
+
+func (e *WordExecutionError) WithLocation(loc *CodeLocation) *WordExecutionError {
+	e.ForthicError.WithLocation(loc)
+	return e
+}
+
+func (e *WordExecutionError) WithForthic(forthic string) *WordExecutionError {
+	e.ForthicError.WithForthic(forthic)
+	return e
+}
+
+func (e *WordExecutionError) WithCause(cause error) *WordExecutionError {
+	e.ForthicError.WithCause(cause)
+	return e
+}
+
+func (e *MissingSemicolonError) WithLocation(loc *CodeLocation) *MissingSemicolonError {
+	e.ForthicError.WithLocation(loc)
+	return e
+}
+
+func (e *MissingSemicolonError) WithForthic(forthic string) *MissingSemicolonError {
+	e.ForthicError.WithForthic(forthic)
+	return e
+}
+
+func (e *MissingSemicolonError) WithCause(cause error) *MissingSemicolonError {
+	e.ForthicError.WithCause(cause)
+	return e
+}
+
+func (e *ExtraSemicolonError) WithLocation(loc *CodeLocation) *ExtraSemicolonError {
+	e.ForthicError.WithLocation(loc)
+	return e
+}
+
+func (e *ExtraSemicolonError) WithForthic(forthic string) *ExtraSemicolonError {
+	e.ForthicError.WithForthic(forthic)
+	return e
+}
+
+func (e *ExtraSemicolonError) WithCause(cause error) *ExtraSemicolonError {
+	e.ForthicError.WithCause(cause)
+	return e
+}
+
+func (e *ModuleError) WithLocation(loc *CodeLocation) *ModuleError {
+	e.ForthicError.WithLocation(loc)
+	return e
+}
+
+func (e *ModuleError) WithForthic(forthic string) *ModuleError {
+	e.ForthicError.WithForthic(forthic)
+	return e
+}
+
+func (e *ModuleError) WithCause(cause error) *ModuleError {
+	e.ForthicError.WithCause(cause)
+	return e
+}
+
+func (e *IntentionalStopError) WithLocation(loc *CodeLocation) *IntentionalStopError {
+	e.ForthicError.WithLocation(loc)
+	return e
+}
+
+func (e *IntentionalStopError) WithForthic(forthic string) *IntentionalStopError {
+	e.ForthicError.WithForthic(forthic)
+	return e
+}
+
+func (e *IntentionalStopError) WithCause(cause error) *IntentionalStopError {
+	e.ForthicError.WithCause(cause)
+	return e
+}
+
+func (e *InvalidVariableNameError) WithLocation(loc *CodeLocation) *InvalidVariableNameError {
+	e.ForthicError.WithLocation(loc)
+	return e
+}
+
+func (e *InvalidVariableNameError) WithForthic(forthic string) *InvalidVariableNameError {
+	e.ForthicError.WithForthic(forthic)
+	return e
+}
+
+func (e *InvalidVariableNameError) WithCause(cause error) *InvalidVariableNameError {
+	e.ForthicError.WithCause(cause)
+	return e
+}
+
+func (e *CancelledError) WithLocation(loc *CodeLocation) *CancelledError {
+	e.ForthicError.WithLocation(loc)
+	return e
+}
+
+func (e *CancelledError) WithForthic(forthic string) *CancelledError {
+	e.ForthicError.WithForthic(forthic)
+	return e
+}
+
+func (e *CancelledError) WithCause(cause error) *CancelledError {
+	e.ForthicError.WithCause(cause)
+	return e
+}
+
+func (e *DeadlineExceededError) WithLocation(loc *CodeLocation) *DeadlineExceededError {
+	e.ForthicError.WithLocation(loc)
+	return e
+}
+
+func (e *DeadlineExceededError) WithForthic(forthic string) *DeadlineExceededError {
+	e.ForthicError.WithForthic(forthic)
+	return e
+}
+
+func (e *DeadlineExceededError) WithCause(cause error) *DeadlineExceededError {
+	e.ForthicError.WithCause(cause)
+	return e
+}
+
+func (e *ResourceLimitError) WithLocation(loc *CodeLocation) *ResourceLimitError {
+	e.ForthicError.WithLocation(loc)
+	return e
+}
+
+func (e *ResourceLimitError) WithForthic(forthic string) *ResourceLimitError {
+	e.ForthicError.WithForthic(forthic)
+	return e
+}
+
+func (e *ResourceLimitError) WithCause(cause error) *ResourceLimitError {
+	e.ForthicError.WithCause(cause)
+	return e
+}
+
+func (e *InvalidOptionError) WithLocation(loc *CodeLocation) *InvalidOptionError {
+	e.ForthicError.WithLocation(loc)
+	return e
+}
+
+func (e *InvalidOptionError) WithForthic(forthic string) *InvalidOptionError {
+	e.ForthicError.WithForthic(forthic)
+	return e
+}
+
+func (e *InvalidOptionError) WithCause(cause error) *InvalidOptionError {
+	e.ForthicError.WithCause(cause)
+	return e
+}
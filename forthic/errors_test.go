@@ -0,0 +1,96 @@
+package forthic
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrors_WithLocationKeepsType(t *testing.T) {
+	loc := &CodeLocation{Line: 3, Column: 7}
+
+	var err error = NewStackUnderflowError().WithLocation(loc)
+	var underflow *StackUnderflowError
+	assert.True(t, errors.As(err, &underflow))
+	assert.Equal(t, loc, underflow.Location)
+
+	err = NewMissingSemicolonError().WithLocation(loc).WithForthic(": FOO")
+	var missing *MissingSemicolonError
+	assert.True(t, errors.As(err, &missing))
+	assert.Equal(t, ": FOO", missing.Forthic)
+
+	cause := errors.New("boom")
+	err = NewModuleError("mymodule", "failed").WithCause(cause).WithLocation(loc)
+	var moduleErr *ModuleError
+	assert.True(t, errors.As(err, &moduleErr))
+	assert.Equal(t, "mymodule", moduleErr.Module)
+	assert.True(t, errors.Is(err, cause))
+}
+
+func TestErrors_AsBaseForthicError(t *testing.T) {
+	var err error = NewUnknownWordError("FOO")
+	var base *ForthicError
+	assert.True(t, errors.As(err, &base))
+	assert.Equal(t, "Unknown word: FOO", base.Message)
+}
+
+func TestErrors_Sentinels(t *testing.T) {
+	err := NewUnknownWordError("FOO")
+	assert.True(t, errors.Is(err, ErrUnknownWord))
+	assert.True(t, errors.Is(err, ErrForthic))
+	assert.False(t, errors.Is(err, ErrStackUnderflow))
+
+	// Sentinels match through wrapping
+	wrapped := fmt.Errorf("request failed: %w", NewWordExecutionError("BAR", NewStackUnderflowError()))
+	assert.True(t, errors.Is(wrapped, ErrWordExecution))
+	assert.True(t, errors.Is(wrapped, ErrStackUnderflow))
+	assert.False(t, errors.Is(errors.New("plain"), ErrForthic))
+}
+
+func TestErrors_Codes(t *testing.T) {
+	assert.Equal(t, CODE_UNKNOWN_WORD, NewUnknownWordError("FOO").Code())
+	assert.Equal(t, "FORTHIC_UNKNOWN_WORD", NewUnknownWordError("FOO").Code())
+	assert.Equal(t, CODE_ERROR, NewForthicError("generic").Code())
+	assert.Equal(t, CODE_RESOURCE_LIMIT, NewResourceLimitError(LIMIT_INSTRUCTIONS, 10).Code())
+
+	wrapped := fmt.Errorf("request failed: %w", NewExtraSemicolonError())
+	assert.Equal(t, CODE_EXTRA_SEMICOLON, GetErrorCode(wrapped))
+	assert.Equal(t, "", GetErrorCode(errors.New("plain")))
+}
+
+func TestErrors_InterpreterErrorsKeepType(t *testing.T) {
+	interp := NewInterpreter()
+
+	err := interp.Run(": FOO 1 2")
+	var missing *MissingSemicolonError
+	assert.True(t, errors.As(err, &missing))
+	assert.NotNil(t, missing.Location)
+	assert.Equal(t, CODE_MISSING_SEMICOLON, GetErrorCode(err))
+
+	err = interp.Run("1 ;")
+	var extra *ExtraSemicolonError
+	assert.True(t, errors.As(err, &extra))
+	assert.NotNil(t, extra.Location)
+
+	err = interp.Run("NO-SUCH-WORD")
+	var unknown *UnknownWordError
+	assert.True(t, errors.As(err, &unknown))
+	assert.Equal(t, "NO-SUCH-WORD", unknown.Word)
+	assert.True(t, errors.Is(err, ErrUnknownWord))
+}
+
+func TestErrors_CloneKeepsTypeAndTrace(t *testing.T) {
+	original := NewUnknownWordError("FOO")
+	AddStackFrame(original, StackFrame{Word: "OUTER"})
+
+	cloned := cloneError(original, "MEMO")
+	AddStackFrame(cloned, StackFrame{Word: "CALLER"})
+
+	var unknown *UnknownWordError
+	assert.True(t, errors.As(cloned, &unknown))
+	assert.Equal(t, "FOO", unknown.Word)
+	assert.Len(t, GetStackTrace(original), 1)
+	assert.Len(t, GetStackTrace(cloned), 2)
+}
@@ -0,0 +1,244 @@
+package forthic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// DefaultLogStackItems is the number of stack items, from the top, included
+// in each execution log record
+const DefaultLogStackItems = 5
+
+// maxLogValueLen bounds the rendered length of a single logged stack item
+const maxLogValueLen = 80
+
+// ============================================================================
+// Execution Log Configuration
+// ============================================================================
+
+// SetLogHandler sets the slog handler that receives execution log records
+// If no handler is set, records go to slog.Default().
+func (i *Interpreter) SetLogHandler(handler slog.Handler) {
+	i.logHandler = handler
+}
+
+// SetLogWriter sends execution log records to w as slog text records
+func (i *Interpreter) SetLogWriter(w io.Writer) {
+	i.logHandler = slog.NewTextHandler(w, nil)
+}
+
+// SetLogStackItems sets how many stack items, from the top, each record includes
+func (i *Interpreter) SetLogStackItems(n int) {
+	i.logStackItems = n
+}
+
+// StartLog starts logging every executed word
+func (i *Interpreter) StartLog() {
+	i.isLogging = true
+}
+
+// EndLog stops logging executed words
+func (i *Interpreter) EndLog() {
+	i.isLogging = false
+}
+
+// IsLogging returns true while execution logging is active
+func (i *Interpreter) IsLogging() bool {
+	return i.isLogging
+}
+
+// ============================================================================
+// Log Records
+// ============================================================================
+
+// logHandlerOrDefault returns the configured handler, or the default slog handler
+func (i *Interpreter) logHandlerOrDefault() slog.Handler {
+	if i.logHandler != nil {
+		return i.logHandler
+	}
+	return slog.Default().Handler()
+}
+
+// logWordExecution emits one record for an executed word
+func (i *Interpreter) logWordExecution(word Word, location *CodeLocation, before []string, err error) {
+	handler := i.logHandlerOrDefault()
+	ctx := context.Background()
+	if !handler.Enabled(ctx, slog.LevelInfo) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("word", word.GetName()),
+		slog.Int("depth", i.callDepth),
+		slog.Any("stack_before", before),
+		slog.Any("stack_after", i.stackSnapshot()),
+	}
+	if location != nil {
+		attrs = append(attrs, slog.String("location", location.String()))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+
+	slog.New(handler).LogAttrs(ctx, slog.LevelInfo, "forthic word", attrs...)
+}
+
+// stackSnapshot renders up to logStackItems items from the top of the stack
+// The top of the stack is the last element.
+func (i *Interpreter) stackSnapshot() []string {
+	items := i.stack.RawItems()
+	start := len(items) - i.logStackItems
+	if start < 0 {
+		start = 0
+	}
+
+	result := make([]string, 0, len(items)-start)
+	for _, item := range items[start:] {
+		result = append(result, renderLogValue(item))
+	}
+	return result
+}
+
+// renderLogValue renders a stack item for logging, truncating long values
+// Arrays and records are rendered element by element and rendering stops once
+// the length limit is reached, so logging a large value costs no more than a
+// small one.
+func renderLogValue(val interface{}) string {
+	w := &logValueWriter{}
+	w.render(reflect.ValueOf(val))
+	if w.truncated {
+		return w.buf.String() + "..."
+	}
+	return w.buf.String()
+}
+
+// logValueWriter accumulates at most maxLogValueLen bytes of a rendered value
+type logValueWriter struct {
+	buf       strings.Builder
+	truncated bool
+}
+
+// write appends s, keeping only what fits within maxLogValueLen
+func (w *logValueWriter) write(s string) {
+	if w.truncated {
+		return
+	}
+	remaining := maxLogValueLen - w.buf.Len()
+	if len(s) > remaining {
+		w.buf.WriteString(s[:remaining])
+		w.truncated = true
+		return
+	}
+	w.buf.WriteString(s)
+}
+
+// render writes val as JSON, walking arrays and records so that it can stop early
+func (w *logValueWriter) render(val reflect.Value) {
+	if w.truncated {
+		return
+	}
+
+	switch val.Kind() {
+	case reflect.Invalid:
+		w.write("null")
+		return
+	case reflect.Interface, reflect.Ptr:
+		if val.IsNil() {
+			w.write("null")
+			return
+		}
+		if val.Kind() == reflect.Interface {
+			w.render(val.Elem())
+			return
+		}
+	case reflect.String:
+		// Only the part of the string that can be shown is encoded
+		str := val.String()
+		if len(str) > maxLogValueLen {
+			str = str[:maxLogValueLen+1]
+		}
+		w.renderJSON(str)
+		return
+	case reflect.Slice, reflect.Array:
+		if val.Kind() == reflect.Slice && val.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		if val.Kind() == reflect.Slice && val.IsNil() {
+			w.write("null")
+			return
+		}
+		w.write("[")
+		for j := 0; j < val.Len() && !w.truncated; j++ {
+			if j > 0 {
+				w.write(",")
+			}
+			w.render(val.Index(j))
+		}
+		w.write("]")
+		return
+	case reflect.Map:
+		if val.Type().Key().Kind() != reflect.String {
+			break
+		}
+		if val.IsNil() {
+			w.write("null")
+			return
+		}
+		w.write("{")
+		for j, key := range smallestKeys(val, maxLogValueLen/4+1) {
+			if w.truncated {
+				break
+			}
+			if j > 0 {
+				w.write(",")
+			}
+			w.renderJSON(key)
+			w.write(":")
+			w.render(val.MapIndex(reflect.ValueOf(key).Convert(val.Type().Key())))
+		}
+		if val.Len() > maxLogValueLen/4+1 {
+			w.truncated = true
+		}
+		w.write("}")
+		return
+	}
+
+	w.renderJSON(val.Interface())
+}
+
+// renderJSON writes a single value with json.Marshal, falling back to fmt
+func (w *logValueWriter) renderJSON(val interface{}) {
+	if b, err := json.Marshal(val); err == nil {
+		w.write(string(b))
+	} else {
+		w.write(fmt.Sprintf("%v", val))
+	}
+}
+
+// smallestKeys returns up to n of a map's keys in sorted order
+// Only the first few entries of a record fit in a log value, so there is no
+// need to sort every key of a large record.
+func smallestKeys(m reflect.Value, n int) []string {
+	keys := make([]string, 0, n+1)
+	iter := m.MapRange()
+	for iter.Next() {
+		key := iter.Key().String()
+		if len(keys) == n && key >= keys[n-1] {
+			continue
+		}
+		pos := sort.SearchStrings(keys, key)
+		keys = append(keys, "")
+		copy(keys[pos+1:], keys[pos:])
+		keys[pos] = key
+		if len(keys) > n {
+			keys = keys[:n]
+		}
+	}
+	return keys
+}
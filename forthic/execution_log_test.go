@@ -0,0 +1,137 @@
+package forthic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingHandler collects slog records for inspection
+type recordingHandler struct {
+	records []slog.Record
+}
+
+func (h *recordingHandler) Enabled(context.Context, slog.Level) bool { return true }
+func (h *recordingHandler) WithAttrs([]slog.Attr) slog.Handler       { return h }
+func (h *recordingHandler) WithGroup(string) slog.Handler            { return h }
+func (h *recordingHandler) Handle(_ context.Context, r slog.Record) error {
+	h.records = append(h.records, r)
+	return nil
+}
+
+func recordAttrs(r slog.Record) map[string]slog.Value {
+	result := make(map[string]slog.Value)
+	r.Attrs(func(a slog.Attr) bool {
+		result[a.Key] = a.Value
+		return true
+	})
+	return result
+}
+
+func TestExecutionLog_Handler(t *testing.T) {
+	interp := NewInterpreter()
+	handler := &recordingHandler{}
+	interp.SetLogHandler(handler)
+
+	interp.StartLog()
+	assert.NoError(t, interp.Run(`: PAIR 1 2 ; PAIR`))
+	interp.EndLog()
+
+	// PAIR, then 1 and 2 inside its definition
+	assert.Equal(t, 3, len(handler.records))
+
+	attrs := recordAttrs(handler.records[0])
+	assert.Equal(t, "1", attrs["word"].String())
+	assert.Equal(t, []string{}, attrs["stack_before"].Any())
+	assert.Equal(t, []string{"1"}, attrs["stack_after"].Any())
+
+	attrs = recordAttrs(handler.records[2])
+	assert.Equal(t, "PAIR", attrs["word"].String())
+	assert.Equal(t, "line 1, col 14", attrs["location"].String())
+	assert.Equal(t, []string{"1", "2"}, attrs["stack_after"].Any())
+}
+
+func TestExecutionLog_BoundedSnapshot(t *testing.T) {
+	interp := NewInterpreter()
+	handler := &recordingHandler{}
+	interp.SetLogHandler(handler)
+	interp.SetLogStackItems(2)
+
+	interp.StartLog()
+	assert.NoError(t, interp.Run(`1 2 3 "`+strings.Repeat("x", 200)+`"`))
+
+	attrs := recordAttrs(handler.records[3])
+	after := attrs["stack_after"].Any().([]string)
+	assert.Equal(t, 2, len(after))
+	assert.Equal(t, "3", after[0])
+	assert.True(t, strings.HasSuffix(after[1], "..."))
+	assert.LessOrEqual(t, len(after[1]), maxLogValueLen+3)
+}
+
+func TestExecutionLog_RecordsErrors(t *testing.T) {
+	interp := NewInterpreter()
+	var buf bytes.Buffer
+	interp.SetLogWriter(&buf)
+
+	interp.StartLog()
+	assert.Error(t, interp.Run(`]`))
+	assert.Contains(t, buf.String(), "error=")
+}
+
+func TestExecutionLog_OffByDefault(t *testing.T) {
+	interp := NewInterpreter()
+	handler := &recordingHandler{}
+	interp.SetLogHandler(handler)
+
+	assert.NoError(t, interp.Run(`1 2 3`))
+	assert.False(t, interp.IsLogging())
+	assert.Empty(t, handler.records)
+}
+
+func TestExecutionLog_RenderMatchesJSON(t *testing.T) {
+	values := []interface{}{
+		nil,
+		42,
+		3.5,
+		"text",
+		[]interface{}{1, "two", nil, []interface{}{3.0}},
+		map[string]interface{}{"b": 2, "a": []interface{}{1}, "c": nil},
+		strings.Repeat("x", 200),
+		[]interface{}{strings.Repeat("y", 50), strings.Repeat("z", 50)},
+	}
+
+	for _, val := range values {
+		b, err := json.Marshal(val)
+		assert.NoError(t, err)
+		expected := string(b)
+		if len(expected) > maxLogValueLen {
+			expected = expected[:maxLogValueLen] + "..."
+		}
+		assert.Equal(t, expected, renderLogValue(val))
+	}
+}
+
+func TestExecutionLog_RenderLargeValues(t *testing.T) {
+	array := make([]interface{}, 1000000)
+	for i := range array {
+		array[i] = i
+	}
+	record := make(map[string]interface{}, 100000)
+	for i := 0; i < 100000; i++ {
+		record[fmt.Sprintf("key%06d", i)] = array
+	}
+
+	rendered := renderLogValue(array)
+	assert.True(t, strings.HasPrefix(rendered, "[0,1,2,3"))
+	assert.Equal(t, maxLogValueLen+3, len(rendered))
+
+	rendered = renderLogValue(record)
+	assert.True(t, strings.HasPrefix(rendered, `{"key000000":[0,1,2`))
+	assert.Equal(t, maxLogValueLen+3, len(rendered))
+}
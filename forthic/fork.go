@@ -0,0 +1,172 @@
+package forthic
+
+import "context"
+
+// ============================================================================
+// Forking
+// ============================================================================
+
+// Fork returns an independent interpreter running the same program
+//
+// The fork starts with a copy of the interpreter's stack, module stack,
+// modules and variables, and then evolves separately: words, variables and
+// imports added to one are not seen by the other, and each has its own
+// variable values. Definitions, module words and memos are shared rather
+// than copied, and module dictionaries are copied only when one side adds
+// to them, so forking a loaded program is cheap.
+//
+// # Concurrency
+//
+// An Interpreter is not safe for concurrent use; each must be used by one
+// goroutine at a time. Fork is how a program runs on several goroutines:
+// fork once per goroutine, from the goroutine that owns the interpreter
+// (between runs, or from a module word while a run is in progress). The
+// interpreter and its forks may then run concurrently.
+//
+// Because forks share words, Go module words must be safe for concurrent
+// use if forks run concurrently, as must a module loader set with
+// SetModuleLoader. Memo words are safe and are shared, so a memo computed
+// by one fork is cached for all. Variable values are copied shallowly: a
+// fork gets its own variables, but an array or record held in one is the
+// same value in both, and must not be modified in place while forks run.
+//
+// The fork has the interpreter's limits, literal handlers, timezone, module
+// factories, module loader and transactional mode. It does not inherit
+// observers, profiling or logging, or the context of a RunContext call.
+//
+// A fork made while a run is in progress is part of that run for resource
+// limits: words it executes count against the run's MaxInstructions, and
+// its call depth starts at the run's. Once that run finishes, the fork's
+// next run is a top-level run of its own.
+func (i *Interpreter) Fork() *Interpreter {
+	fork := &Interpreter{
+		stack:           NewStack(i.stack.Items()...),
+		registeredMods:  make(map[string]*Module, len(i.registeredMods)),
+		moduleFactories: make(map[string]ModuleFactory, len(i.moduleFactories)),
+		moduleLoader:    i.moduleLoader,
+		tokenizerStack:  make([]*Tokenizer, 0),
+		literalHandlers: append([]LiteralHandler(nil), i.literalHandlers...),
+		timezone:        i.timezone,
+		compileCache:    newCompileCache(i.compileCache.capacity),
+		ctx:             context.Background(),
+		limits:          i.limits,
+		callDepth:       i.callDepth,
+		baseCallDepth:   i.callDepth,
+		budget:          i.budget,
+		logStackItems:   i.logStackItems,
+		transactional:   i.transactional,
+	}
+	for name, factory := range i.moduleFactories {
+		fork.moduleFactories[name] = factory
+	}
+
+	forker := &moduleForker{
+		from:      i,
+		to:        fork,
+		modules:   make(map[*Module]*Module),
+		variables: make(map[*Variable]*Variable),
+	}
+	fork.appModule = forker.fork(i.appModule)
+	for name, module := range i.registeredMods {
+		fork.registeredMods[name] = forker.fork(module)
+	}
+	fork.moduleStack = make([]*Module, len(i.moduleStack))
+	for j, module := range i.moduleStack {
+		fork.moduleStack[j] = forker.fork(module)
+	}
+
+	// Shared definitions refer to the variables they were compiled with;
+	// the fork pushes its own copies of those variables instead
+	fork.variableCopies = forker.variables
+	for original, copied := range i.variableCopies {
+		if forked, ok := forker.variables[copied]; ok {
+			fork.variableCopies[original] = forked
+		}
+	}
+	return fork
+}
+
+// resolveVariable returns this interpreter's copy of a variable
+// Variables are copied when an interpreter is forked; see Fork.
+func (i *Interpreter) resolveVariable(variable *Variable) *Variable {
+	if copied, ok := i.variableCopies[variable]; ok {
+		return copied
+	}
+	return variable
+}
+
+// moduleForker copies an interpreter's modules for a fork
+type moduleForker struct {
+	from      *Interpreter
+	to        *Interpreter
+	modules   map[*Module]*Module     // original -> fork's module
+	variables map[*Variable]*Variable // original -> fork's variable
+}
+
+// fork returns the fork's copy of module, creating it if needed
+// The copy shares the module's dictionary until either side changes it.
+func (f *moduleForker) fork(module *Module) *Module {
+	if copied, ok := f.modules[module]; ok {
+		return copied
+	}
+
+	module.shared = true
+	copied := *module
+	f.modules[module] = &copied
+
+	copied.variables = make(map[string]*Variable, len(module.variables))
+	copied.variableWords = make(map[string]Word, len(module.variableWords))
+	for name, variable := range module.variables {
+		forked := variable.Dup()
+		f.variables[variable] = forked
+		copied.variables[name] = forked
+		copied.variableWords[name] = NewPushValueWord(name, forked)
+	}
+
+	copied.modules = make(map[string]*Module, len(module.modules))
+	for name, child := range module.modules {
+		copied.modules[name] = f.fork(child)
+	}
+
+	if copied.interp == f.from {
+		copied.interp = f.to
+	}
+	return &copied
+}
+
+// unshare gives a module that shares its dictionary with a fork its own copy
+// Called before the module's dictionary changes.
+func (m *Module) unshare() {
+	if !m.shared {
+		return
+	}
+	m.shared = false
+
+	words := make([]Word, len(m.words))
+	copy(words, m.words)
+	m.words = words
+
+	wordIndex := make(map[string]Word, len(m.wordIndex))
+	for name, word := range m.wordIndex {
+		wordIndex[name] = word
+	}
+	m.wordIndex = wordIndex
+
+	exportable := make([]string, len(m.exportable))
+	copy(exportable, m.exportable)
+	m.exportable = exportable
+
+	definitions := make([]*DefinitionWord, len(m.definitions))
+	copy(definitions, m.definitions)
+	m.definitions = definitions
+
+	modulePrefixes := make(map[string]map[string]bool, len(m.modulePrefixes))
+	for name, prefixes := range m.modulePrefixes {
+		copied := make(map[string]bool, len(prefixes))
+		for prefix := range prefixes {
+			copied[prefix] = true
+		}
+		modulePrefixes[name] = copied
+	}
+	m.modulePrefixes = modulePrefixes
+}
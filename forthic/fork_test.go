@@ -0,0 +1,161 @@
+package forthic
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newVarsInterpreter returns an interpreter with words to get, set and
+// increment variables, and a counter variable used by a definition
+func newVarsInterpreter(t *testing.T) *Interpreter {
+	module := NewModule("vars")
+	module.AddModuleWord("@", func(interp *Interpreter) error {
+		interp.StackPush(interp.StackPop().(*Variable).GetValue())
+		return nil
+	})
+	module.AddModuleWord("!", func(interp *Interpreter) error {
+		variable := interp.StackPop().(*Variable)
+		variable.SetValue(interp.StackPop())
+		return nil
+	})
+	module.AddModuleWord("INC", func(interp *Interpreter) error {
+		variable := interp.StackPop().(*Variable)
+		variable.SetValue(variable.GetValue().(int64) + 1)
+		return nil
+	})
+	module.AddModuleWord("VARIABLE", func(interp *Interpreter) error {
+		interp.CurModule().AddVariable(interp.StackPop().(string), int64(0))
+		return nil
+	})
+
+	interp := NewInterpreter(module)
+	require.NoError(t, interp.Run(`"count" VARIABLE  : BUMP count INC ;  : COUNT count @ ;`))
+	return interp
+}
+
+func TestFork_OwnStackAndVariables(t *testing.T) {
+	interp := newVarsInterpreter(t)
+	require.NoError(t, interp.Run(`BUMP "base"`))
+
+	fork := interp.Fork()
+	assert.Equal(t, []interface{}{"base"}, fork.GetStack().Items())
+
+	require.NoError(t, fork.Run(`BUMP BUMP COUNT`))
+	require.NoError(t, interp.Run(`COUNT`))
+
+	assert.Equal(t, []interface{}{"base", int64(3)}, fork.GetStack().Items())
+	assert.Equal(t, []interface{}{"base", int64(1)}, interp.GetStack().Items())
+	assert.Equal(t, int64(1), interp.GetAppModule().GetVariable("count").GetValue())
+	assert.Equal(t, int64(3), fork.GetAppModule().GetVariable("count").GetValue())
+}
+
+func TestFork_SharesDefinitions(t *testing.T) {
+	interp := newVarsInterpreter(t)
+	fork := interp.Fork()
+
+	assert.Same(t, interp.GetAppModule().FindWord("BUMP"), fork.GetAppModule().FindWord("BUMP"))
+}
+
+func TestFork_DictionariesAreCopiedOnWrite(t *testing.T) {
+	interp := newVarsInterpreter(t)
+	fork := interp.Fork()
+
+	require.NoError(t, fork.Run(`: ONLY-FORK 1 ;  "fork-var" VARIABLE  {scratch : X 2 ; }`))
+	require.NoError(t, interp.Run(`: ONLY-PARENT 3 ;  : BUMP 4 ;`))
+
+	assert.Nil(t, interp.GetAppModule().FindWord("ONLY-FORK"))
+	assert.Nil(t, interp.GetAppModule().GetVariable("fork-var"))
+	assert.Nil(t, interp.GetAppModule().FindModule("scratch"))
+	assert.Nil(t, fork.GetAppModule().FindWord("ONLY-PARENT"))
+
+	require.NoError(t, fork.Run(`BUMP COUNT`))
+	require.NoError(t, interp.Run(`BUMP`))
+	assert.Equal(t, []interface{}{int64(1)}, fork.GetStack().Items())
+	assert.Equal(t, []interface{}{int64(4)}, interp.GetStack().Items())
+}
+
+func TestFork_ForkOfFork(t *testing.T) {
+	interp := newVarsInterpreter(t)
+	child := interp.Fork()
+	require.NoError(t, child.Run(`BUMP`))
+
+	grandchild := child.Fork()
+	require.NoError(t, grandchild.Run(`BUMP BUMP COUNT`))
+	require.NoError(t, child.Run(`COUNT`))
+	require.NoError(t, interp.Run(`COUNT`))
+
+	assert.Equal(t, []interface{}{int64(3)}, grandchild.GetStack().Items())
+	assert.Equal(t, []interface{}{int64(1)}, child.GetStack().Items())
+	assert.Equal(t, []interface{}{int64(0)}, interp.GetStack().Items())
+}
+
+func TestFork_ConcurrentRuns(t *testing.T) {
+	interp := newVarsInterpreter(t)
+	require.NoError(t, interp.Run(`@: SHARED 42 ;`))
+
+	const numForks = 8
+	forks := make([]*Interpreter, numForks)
+	for j := range forks {
+		forks[j] = interp.Fork()
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, numForks)
+	for j, fork := range forks {
+		wg.Add(1)
+		go func(j int, fork *Interpreter) {
+			defer wg.Done()
+			code := fmt.Sprintf(`: MINE %d ;  "local" VARIABLE  SHARED`, j)
+			for n := 0; n <= j; n++ {
+				code += " BUMP"
+			}
+			errs[j] = fork.Run(code + " COUNT MINE")
+		}(j, fork)
+	}
+
+	// The parent keeps running too
+	for n := 0; n < 100; n++ {
+		require.NoError(t, interp.Run(`BUMP`))
+	}
+	wg.Wait()
+
+	for j, fork := range forks {
+		require.NoError(t, errs[j])
+		assert.Equal(t, []interface{}{int64(42), int64(j + 1), int64(j)}, fork.GetStack().Items())
+	}
+	assert.Equal(t, int64(100), interp.GetAppModule().GetVariable("count").GetValue())
+}
+
+func TestFork_SharesRunLimits(t *testing.T) {
+	interp := newVarsInterpreter(t)
+	var fork *Interpreter
+	module := NewModule("forking")
+	module.AddModuleWord("FORK-RUN", func(interp *Interpreter) error {
+		fork = interp.Fork()
+		return fork.Run(interp.StackPop().(string))
+	})
+	require.NoError(t, interp.ImportModule(module, ""))
+
+	// The fork's words count against the run that made it
+	interp.SetLimits(Limits{MaxInstructions: 5})
+	err := interp.Run(`"1 2 3 4" FORK-RUN`)
+	var limitErr *ResourceLimitError
+	require.True(t, errors.As(err, &limitErr), "got %v", err)
+	assert.Equal(t, LIMIT_INSTRUCTIONS, limitErr.Limit)
+
+	// Once the run is over, the fork runs on its own budget and call depth
+	require.NoError(t, fork.Run(`1 2 3 4`))
+	assert.Equal(t, 0, fork.callDepth)
+
+	// The fork's call depth starts at the run's
+	interp.SetLimits(Limits{MaxCallDepth: 2})
+	require.NoError(t, interp.Run(`"1" FORK-RUN`))
+	err = interp.Run(`: A "1" FORK-RUN ; A`)
+	require.True(t, errors.As(err, &limitErr), "got %v", err)
+	assert.Equal(t, LIMIT_CALL_DEPTH, limitErr.Limit)
+}
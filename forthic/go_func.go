@@ -0,0 +1,326 @@
+package forthic
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// ============================================================================
+// Go Function Words
+// ============================================================================
+
+var (
+	errorType       = reflect.TypeOf((*error)(nil)).Elem()
+	interpreterType = reflect.TypeOf((*Interpreter)(nil))
+)
+
+// AddGoFunc adds a word that calls a Go function, and marks it as exportable
+//
+// The word pops one argument per parameter (the last parameter is the top
+// of the stack) and converts each to the parameter's type. Forthic values
+// convert to Go types as you'd expect: integers and floats to any numeric
+// type that can hold them exactly, arrays to slices and records to maps with
+// string keys (converting their elements), and NULL to the zero value of
+// pointers, slices, maps and interfaces. A value that can't be converted
+// fails the word with an error naming the argument, and leaves the arguments
+// on the stack.
+//
+// The function's results are pushed in order, with integers pushed as int64
+// (or, for unsigned integers too large for one, float64), floats as float64,
+// slices as arrays and maps as records. If the last
+// result is an error, it isn't pushed; a non-nil error fails the word.
+//
+// A first parameter of type *Interpreter receives the interpreter rather
+// than a stack value.
+//
+//	module.AddGoFunc("REPEAT", strings.Repeat) // "ab" 3 REPEAT -> "ababab"
+//
+// AddGoFunc returns an error if fn isn't a function, is variadic, or has a
+// parameter type that Forthic values can't convert to (functions, channels
+// and complex numbers, or maps without string keys).
+func (m *Module) AddGoFunc(name string, fn interface{}) error {
+	word, err := NewGoFuncWord(name, fn)
+	if err != nil {
+		return err
+	}
+	m.AddExportableWord(word)
+	return nil
+}
+
+// NewGoFuncWord creates a ModuleWord that calls a Go function; see AddGoFunc
+func NewGoFuncWord(name string, fn interface{}) (*ModuleWord, error) {
+	binding, err := bindGoFunc(name, fn)
+	if err != nil {
+		return nil, err
+	}
+	return NewModuleWord(name, binding.call), nil
+}
+
+// goFunc is a Go function bound to a word
+type goFunc struct {
+	name       string
+	fn         reflect.Value
+	withInterp bool           // the first parameter is the interpreter
+	params     []reflect.Type // parameters taken from the stack
+	returnsErr bool           // the last result is an error
+	numResults int            // results to push
+}
+
+func bindGoFunc(name string, fn interface{}) (*goFunc, error) {
+	value := reflect.ValueOf(fn)
+	if value.Kind() != reflect.Func || value.IsNil() {
+		return nil, NewForthicError(fmt.Sprintf("Cannot add %s: %T is not a function", name, fn))
+	}
+	fnType := value.Type()
+	if fnType.IsVariadic() {
+		return nil, NewForthicError(fmt.Sprintf("Cannot add %s: variadic functions are not supported", name))
+	}
+
+	binding := &goFunc{name: name, fn: value}
+	for j := 0; j < fnType.NumIn(); j++ {
+		param := fnType.In(j)
+		if j == 0 && param == interpreterType {
+			binding.withInterp = true
+			continue
+		}
+		if !convertibleType(param) {
+			return nil, NewForthicError(fmt.Sprintf("Cannot add %s: unsupported parameter type %s", name, param))
+		}
+		binding.params = append(binding.params, param)
+	}
+
+	binding.numResults = fnType.NumOut()
+	if binding.numResults > 0 && fnType.Out(binding.numResults-1) == errorType {
+		binding.returnsErr = true
+		binding.numResults--
+	}
+	return binding, nil
+}
+
+// convertibleType reports whether Forthic values can be converted to t
+func convertibleType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Slice:
+		return convertibleType(t.Elem())
+	case reflect.Map:
+		return t.Key().Kind() == reflect.String && convertibleType(t.Elem())
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
+		return false
+	}
+	return true
+}
+
+// call pops the function's arguments, calls it and pushes its results
+func (f *goFunc) call(interp *Interpreter) error {
+	if interp.stack.Length() < len(f.params) {
+		return NewStackUnderflowError().WithLocation(interp.CurrentLocation())
+	}
+
+	// Arguments are only popped once they've all converted, so a failed
+	// conversion leaves the stack as it was
+	items := interp.stack.RawItems()
+	values := items[len(items)-len(f.params):]
+	args := make([]reflect.Value, len(f.params))
+	for j, value := range values {
+		arg, err := toGoValue(value, f.params[j])
+		if err != nil {
+			message := fmt.Sprintf("%s argument %d: %s", f.name, j+1, err.Error())
+			return NewForthicError(message).WithLocation(interp.CurrentLocation())
+		}
+		args[j] = arg
+	}
+	for range f.params {
+		interp.StackPop()
+	}
+	if f.withInterp {
+		args = append([]reflect.Value{reflect.ValueOf(interp)}, args...)
+	}
+
+	results := f.fn.Call(args)
+	if f.returnsErr {
+		if err, _ := results[f.numResults].Interface().(error); err != nil {
+			return err
+		}
+	}
+	for _, result := range results[:f.numResults] {
+		interp.StackPush(fromGoValue(result))
+	}
+	return nil
+}
+
+// conversionError describes a Forthic value that can't become a Go type
+type conversionError struct {
+	value  interface{}
+	goType reflect.Type
+}
+
+func (e *conversionError) Error() string {
+	if e.value == nil {
+		return fmt.Sprintf("cannot convert NULL to %s", e.goType)
+	}
+	return fmt.Sprintf("cannot convert %s to %s", describeValue(e.value), e.goType)
+}
+
+// toGoValue converts a Forthic value to a Go type
+func toGoValue(value interface{}, t reflect.Type) (reflect.Value, error) {
+	fail := func() (reflect.Value, error) {
+		return reflect.Value{}, &conversionError{value: value, goType: t}
+	}
+
+	if value == nil {
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+			return reflect.Zero(t), nil
+		}
+		return fail()
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Type().AssignableTo(t) {
+		return v, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := integerValue(value)
+		if !ok {
+			return fail()
+		}
+		result := reflect.New(t).Elem()
+		if result.OverflowInt(n) {
+			return fail()
+		}
+		result.SetInt(n)
+		return result, nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, ok := integerValue(value)
+		if !ok || n < 0 {
+			return fail()
+		}
+		result := reflect.New(t).Elem()
+		if result.OverflowUint(uint64(n)) {
+			return fail()
+		}
+		result.SetUint(uint64(n))
+		return result, nil
+
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		switch n := value.(type) {
+		case float64:
+			f = n
+		case int64:
+			f = float64(n)
+		case int:
+			f = float64(n)
+		default:
+			return fail()
+		}
+		result := reflect.New(t).Elem()
+		result.SetFloat(f)
+		return result, nil
+
+	case reflect.String, reflect.Bool:
+		// Named string and bool types
+		if v.Kind() == t.Kind() {
+			return v.Convert(t), nil
+		}
+
+	case reflect.Slice:
+		arr, ok := value.([]interface{})
+		if !ok {
+			return fail()
+		}
+		result := reflect.MakeSlice(t, len(arr), len(arr))
+		for j, item := range arr {
+			converted, err := toGoValue(item, t.Elem())
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("element %d: %w", j, err)
+			}
+			result.Index(j).Set(converted)
+		}
+		return result, nil
+
+	case reflect.Map:
+		rec, ok := value.(map[string]interface{})
+		if !ok {
+			return fail()
+		}
+		result := reflect.MakeMapWithSize(t, len(rec))
+		for key, item := range rec {
+			converted, err := toGoValue(item, t.Elem())
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("field %q: %w", key, err)
+			}
+			result.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), converted)
+		}
+		return result, nil
+	}
+	return fail()
+}
+
+// integerValue returns a Forthic number as an int64, if it is a whole number
+func integerValue(value interface{}) (int64, bool) {
+	switch n := value.(type) {
+	case int64:
+		return n, true
+	case int:
+		return int64(n), true
+	case float64:
+		if n == math.Trunc(n) && n >= math.MinInt64 && n < math.MaxInt64 {
+			return int64(n), true
+		}
+	}
+	return 0, false
+}
+
+// fromGoValue converts a Go result to a Forthic value
+func fromGoValue(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n := v.Uint()
+		if n > math.MaxInt64 {
+			return float64(n)
+		}
+		return int64(n)
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		result := make([]interface{}, v.Len())
+		for j := range result {
+			result[j] = fromGoValue(v.Index(j))
+		}
+		return result
+	case reflect.Map:
+		if v.IsNil() || v.Type().Key().Kind() != reflect.String {
+			break
+		}
+		result := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			result[iter.Key().String()] = fromGoValue(iter.Value())
+		}
+		return result
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Kind() == reflect.Interface {
+			return fromGoValue(v.Elem())
+		}
+	case reflect.Invalid:
+		return nil
+	}
+	return v.Interface()
+}
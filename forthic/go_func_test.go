@@ -0,0 +1,147 @@
+package forthic
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type celsius float64
+
+// newGoFuncInterpreter returns an interpreter with words bound to Go functions
+func newGoFuncInterpreter(t *testing.T) *Interpreter {
+	module := NewModule("gofuncs")
+	require.NoError(t, module.AddGoFunc("REPEAT", strings.Repeat))
+	require.NoError(t, module.AddGoFunc("SUM", func(numbers []int) int {
+		total := 0
+		for _, n := range numbers {
+			total += n
+		}
+		return total
+	}))
+	require.NoError(t, module.AddGoFunc("DIVMOD", func(a, b int64) (int64, int64, error) {
+		if b == 0 {
+			return 0, 0, errors.New("division by zero")
+		}
+		return a / b, a % b, nil
+	}))
+	require.NoError(t, module.AddGoFunc("WARM?", func(temp celsius) bool { return temp > 20 }))
+	require.NoError(t, module.AddGoFunc("YEAR", func(t time.Time) int { return t.Year() }))
+	require.NoError(t, module.AddGoFunc("KEYS", func(rec map[string]interface{}) []string {
+		keys := []string{}
+		for key := range rec {
+			keys = append(keys, key)
+		}
+		return keys
+	}))
+	require.NoError(t, module.AddGoFunc("DEPTH", func(interp *Interpreter, marker string) int {
+		return interp.GetStack().Length()
+	}))
+	require.NoError(t, module.AddGoFunc("NOTHING", func() {}))
+	return NewInterpreter(module)
+}
+
+func TestGoFunc_ConvertsArguments(t *testing.T) {
+	interp := newGoFuncInterpreter(t)
+	interp.StackPush([]interface{}{int64(1), 2.0, 3})
+	require.NoError(t, interp.Run(`SUM`))
+	interp.StackPush(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, interp.Run(`YEAR`))
+
+	assert.Equal(t, []interface{}{int64(6), int64(2024)}, interp.GetStack().Items())
+}
+
+func TestGoFunc_ArgumentOrder(t *testing.T) {
+	interp := newGoFuncInterpreter(t)
+	require.NoError(t, interp.Run(`"ab" 3 REPEAT  17 5 DIVMOD  21.5 WARM?  NOTHING`))
+
+	assert.Equal(t, []interface{}{"ababab", int64(3), int64(2), true}, interp.GetStack().Items())
+}
+
+func TestGoFunc_ResultsBecomeForthicValues(t *testing.T) {
+	interp := newGoFuncInterpreter(t)
+	interp.StackPush(map[string]interface{}{"a": 1})
+	require.NoError(t, interp.Run(`KEYS`))
+
+	assert.Equal(t, []interface{}{"a"}, interp.StackPop())
+
+	// Unsigned results too large for an int64 become floats
+	module := NewModule("unsigned")
+	require.NoError(t, module.AddGoFunc("MAX-UINT", func() uint64 { return math.MaxUint64 }))
+	require.NoError(t, module.AddGoFunc("MAX-INT", func() uint64 { return math.MaxInt64 }))
+	interp = NewInterpreter(module)
+	require.NoError(t, interp.Run(`MAX-UINT MAX-INT`))
+	assert.Equal(t, []interface{}{float64(math.MaxUint64), int64(math.MaxInt64)}, interp.GetStack().Items())
+}
+
+func TestGoFunc_InterpreterParameter(t *testing.T) {
+	interp := newGoFuncInterpreter(t)
+	require.NoError(t, interp.Run(`1 2 "marker" DEPTH`))
+
+	assert.Equal(t, int64(2), interp.StackPop())
+}
+
+func TestGoFunc_ErrorResult(t *testing.T) {
+	interp := newGoFuncInterpreter(t)
+	err := interp.Run(`1 0 DIVMOD`)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "division by zero")
+	assert.True(t, errors.Is(err, ErrForthic))
+	assert.Equal(t, 0, interp.GetStack().Length())
+}
+
+func TestGoFunc_ConversionErrors(t *testing.T) {
+	tests := []struct {
+		code    string
+		message string
+	}{
+		{`"ab" "x" REPEAT`, `REPEAT argument 2: cannot convert string "x" to int`},
+		{`"ab" 1.5 REPEAT`, `REPEAT argument 2: cannot convert number 1.5 to int`},
+		{`"warm" WARM?`, `WARM? argument 1: cannot convert string "warm" to forthic.celsius`},
+	}
+	for _, test := range tests {
+		interp := newGoFuncInterpreter(t)
+		err := interp.Run(test.code)
+
+		var forthicErr *ForthicError
+		require.True(t, errors.As(err, &forthicErr), "%s: got %v", test.code, err)
+		assert.Equal(t, test.message, forthicErr.Message)
+	}
+
+	interp := newGoFuncInterpreter(t)
+	interp.StackPush(nil)
+	err := interp.Run(`2 REPEAT`)
+	assert.Contains(t, err.Error(), `REPEAT argument 1: cannot convert NULL to string`)
+
+	interp.StackPush([]interface{}{int64(1), "two"})
+	err = interp.Run(`SUM`)
+	assert.Contains(t, err.Error(), `SUM argument 1: element 1: cannot convert string "two" to int`)
+
+	// No arguments are popped unless they all convert
+	interp = newGoFuncInterpreter(t)
+	err = interp.Run(`"ab" 1.5 REPEAT`)
+	require.Error(t, err)
+	assert.Equal(t, []interface{}{"ab", 1.5}, interp.GetStack().Items())
+}
+
+func TestGoFunc_StackUnderflow(t *testing.T) {
+	interp := newGoFuncInterpreter(t)
+	err := interp.Run(`3 REPEAT`)
+
+	assert.True(t, errors.Is(err, ErrStackUnderflow))
+	assert.Equal(t, []interface{}{int64(3)}, interp.GetStack().Items())
+}
+
+func TestGoFunc_InvalidFunctions(t *testing.T) {
+	module := NewModule("bad")
+	assert.Error(t, module.AddGoFunc("NOT-FUNC", 42))
+	assert.Error(t, module.AddGoFunc("VARIADIC", func(parts ...string) string { return "" }))
+	assert.Error(t, module.AddGoFunc("CHANNEL", func(c chan int) {}))
+	assert.Nil(t, module.FindWord("NOT-FUNC"))
+}
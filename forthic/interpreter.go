@@ -1,10 +1,18 @@
 package forthic
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
+	"os"
+	"sync/atomic"
 	"time"
 )
 
+// maxMissingWords bounds the number of names remembered as not defined in
+// any module on the module stack
+const maxMissingWords = 4096
+
 // LiteralHandler tries to parse a string as a literal value
 // Returns value and true if successful, nil and false otherwise
 type LiteralHandler func(string) (interface{}, bool)
@@ -14,33 +22,74 @@ type LiteralHandler func(string) (interface{}, bool)
 // Core interpreter that tokenizes and executes Forthic code.
 // Manages the data stack, module stack, and execution context.
 type Interpreter struct {
-	stack           *Stack
-	appModule       *Module
-	moduleStack     []*Module
-	registeredMods  map[string]*Module
-	tokenizerStack  []*Tokenizer
-	previousToken   *Token
-	isCompiling     bool
+	stack            *Stack
+	appModule        *Module
+	moduleStack      []*Module
+	registeredMods   map[string]*Module
+	moduleFactories  map[string]ModuleFactory
+	moduleLoader     ModuleLoader
+	loadingModules   []string
+	tokenizerStack   []*Tokenizer
+	previousToken    *Token
+	isCompiling      bool
 	isMemoDefinition bool
-	curDefinition   *DefinitionWord
-	literalHandlers []LiteralHandler
-	timezone        string
+	curDefinition    *DefinitionWord
+	memoTTL          time.Duration // TTL given to the memo being defined
+	literalHandlers  []LiteralHandler
+	timezone         string
+	compileCache     *compileCache
+	ctx              context.Context
+	limits           Limits
+	callDepth        int
+	baseCallDepth    int        // call depth of the run a fork was made during
+	budget           *runBudget // shared with forks made during the current run
+	isProfiling      bool
+	profile          *profile
+	isLogging        bool
+	logHandler       slog.Handler
+	logStackItems    int
+	observers        []Observer
+	literals         []stringLiteral // string literals on the stack, by slot
+	poppedLiteral    *stringLiteral  // string literal popped by the executing word
+	stackVersion     uint64          // incremented whenever the module stack changes
+	missingWords     wordMissCache
+	transactional    bool
+	transaction      *transaction            // active while a transactional Run is running
+	variableCopies   map[*Variable]*Variable // set in forks; see resolveVariable
 }
 
 // NewInterpreter creates a new Interpreter
+// Panics if the Forthic code of a provided module fails; use
+// NewInterpreterWithModules to handle the error instead.
 func NewInterpreter(modules ...*Module) *Interpreter {
+	interp, err := NewInterpreterWithModules(modules...)
+	if err != nil {
+		panic(err)
+	}
+	return interp
+}
+
+// NewInterpreterWithModules creates a new Interpreter with modules imported
+// unprefixed, returning an error if the Forthic code of a module fails
+func NewInterpreterWithModules(modules ...*Module) (*Interpreter, error) {
 	interp := &Interpreter{
-		stack:           NewStack(),
-		appModule:       NewModule(""),
-		moduleStack:     make([]*Module, 0),
-		registeredMods:  make(map[string]*Module),
-		tokenizerStack:  make([]*Tokenizer, 0),
-		previousToken:   nil,
-		isCompiling:     false,
+		stack:            NewStack(),
+		appModule:        NewModule(""),
+		moduleStack:      make([]*Module, 0),
+		registeredMods:   make(map[string]*Module),
+		moduleFactories:  make(map[string]ModuleFactory),
+		tokenizerStack:   make([]*Tokenizer, 0),
+		previousToken:    nil,
+		isCompiling:      false,
 		isMemoDefinition: false,
-		curDefinition:   nil,
-		literalHandlers: make([]LiteralHandler, 0),
-		timezone:        "UTC",
+		curDefinition:    nil,
+		literalHandlers:  make([]LiteralHandler, 0),
+		timezone:         "UTC",
+		compileCache:     newCompileCache(DefaultCompileCacheSize),
+		ctx:              context.Background(),
+		limits:           DefaultLimits(),
+		budget:           &runBudget{},
+		logStackItems:    DefaultLogStackItems,
 	}
 
 	// Set app module's interpreter
@@ -54,10 +103,12 @@ func NewInterpreter(modules ...*Module) *Interpreter {
 
 	// Import provided modules (unprefixed)
 	for _, module := range modules {
-		interp.ImportModule(module, "")
+		if err := interp.ImportModule(module, ""); err != nil {
+			return nil, err
+		}
 	}
 
-	return interp
+	return interp, nil
 }
 
 // ============================================================================
@@ -74,13 +125,10 @@ func (i *Interpreter) StackPush(val interface{}) {
 func (i *Interpreter) StackPop() interface{} {
 	val, err := i.stack.Pop()
 	if err != nil {
-		// Get token location if available
-		var loc *CodeLocation
-		if len(i.tokenizerStack) > 0 {
-			tokenizer := i.GetTokenizer()
-			loc = tokenizer.getTokenLocation()
-		}
-		panic(NewStackUnderflowError().WithLocation(loc))
+		panic(newStackUnderflowAt(i.CurrentLocation()))
+	}
+	if len(i.literals) > 0 {
+		i.popLiteral(val)
 	}
 	return val
 }
@@ -89,17 +137,16 @@ func (i *Interpreter) StackPop() interface{} {
 func (i *Interpreter) StackPeek() interface{} {
 	val, err := i.stack.Peek()
 	if err != nil {
-		// Get token location if available
-		var loc *CodeLocation
-		if len(i.tokenizerStack) > 0 {
-			tokenizer := i.GetTokenizer()
-			loc = tokenizer.getTokenLocation()
-		}
-		panic(NewStackUnderflowError().WithLocation(loc))
+		panic(newStackUnderflowAt(i.CurrentLocation()))
 	}
 	return val
 }
 
+// newStackUnderflowAt creates a StackUnderflowError at the given location
+func newStackUnderflowAt(loc *CodeLocation) *StackUnderflowError {
+	return NewStackUnderflowError().WithLocation(loc)
+}
+
 // GetStack returns the stack
 func (i *Interpreter) GetStack() *Stack {
 	return i.stack
@@ -119,9 +166,20 @@ func (i *Interpreter) CurModule() *Module {
 	return i.moduleStack[len(i.moduleStack)-1]
 }
 
+// ModuleStack returns a copy of the module stack, bottom (app module) first
+func (i *Interpreter) ModuleStack() []*Module {
+	result := make([]*Module, len(i.moduleStack))
+	copy(result, i.moduleStack)
+	return result
+}
+
 // ModuleStackPush pushes a module onto the module stack
 func (i *Interpreter) ModuleStackPush(module *Module) {
 	i.moduleStack = append(i.moduleStack, module)
+	i.stackVersion++
+	for _, o := range i.observers {
+		o.OnModulePush(module)
+	}
 }
 
 // ModuleStackPop pops a module from the module stack
@@ -131,21 +189,63 @@ func (i *Interpreter) ModuleStackPop() *Module {
 	}
 	module := i.moduleStack[len(i.moduleStack)-1]
 	i.moduleStack = i.moduleStack[:len(i.moduleStack)-1]
+	i.stackVersion++
+	for _, o := range i.observers {
+		o.OnModulePop(module)
+	}
 	return module
 }
 
 // RegisterModule registers a module with the interpreter
-func (i *Interpreter) RegisterModule(module *Module) {
+// The first time a module is registered, its Forthic code (if any) is run.
+func (i *Interpreter) RegisterModule(module *Module) error {
 	i.registeredMods[module.name] = module
 	module.SetInterp(i)
+	if err := i.initModule(module, module.name); err != nil {
+		delete(i.registeredMods, module.name)
+		return err
+	}
+	return nil
+}
+
+// RegisterModuleFactory makes a module available to this interpreter by name
+// Factories registered here take precedence over global ones.
+func (i *Interpreter) RegisterModuleFactory(name string, factory ModuleFactory) {
+	i.moduleFactories[name] = factory
 }
 
 // FindModule finds a registered module by name
+// If no module is registered under name, a module factory (interpreter-level
+// first, then global) is used to create and register one. Failing that, the
+// module loader, if set, is used to load one from Forthic source.
 func (i *Interpreter) FindModule(name string) (*Module, error) {
 	module, ok := i.registeredMods[name]
+	if ok {
+		return module, nil
+	}
+
+	factory, ok := i.moduleFactories[name]
 	if !ok {
+		factory, ok = LookupModuleFactory(name)
+	}
+	switch {
+	case ok:
+		module = factory()
+		module.SetInterp(i)
+		if err := i.initModule(module, name); err != nil {
+			return nil, err
+		}
+	case i.moduleLoader != nil:
+		loaded, err := i.loadModule(name)
+		if err != nil {
+			return nil, err
+		}
+		module = loaded
+	default:
 		return nil, NewUnknownModuleError(name)
 	}
+
+	i.registeredMods[name] = module
 	return module, nil
 }
 
@@ -180,9 +280,13 @@ func (i *Interpreter) UseModules(names []interface{}) error {
 }
 
 // ImportModule registers and imports a module
-func (i *Interpreter) ImportModule(module *Module, prefix string) {
-	i.RegisterModule(module)
+// If the module's Forthic code fails, the module is not imported.
+func (i *Interpreter) ImportModule(module *Module, prefix string) error {
+	if err := i.RegisterModule(module); err != nil {
+		return err
+	}
 	i.appModule.ImportModule(prefix, module, i)
+	return nil
 }
 
 // ============================================================================
@@ -194,6 +298,14 @@ func (i *Interpreter) GetTokenizer() *Tokenizer {
 	return i.tokenizerStack[len(i.tokenizerStack)-1]
 }
 
+// CurrentLocation returns the location of the token being handled, if any
+func (i *Interpreter) CurrentLocation() *CodeLocation {
+	if len(i.tokenizerStack) == 0 {
+		return nil
+	}
+	return i.GetTokenizer().getTokenLocation()
+}
+
 // ============================================================================
 // Literal Handlers
 // ============================================================================
@@ -221,6 +333,9 @@ func (i *Interpreter) registerStandardLiterals() {
 func (i *Interpreter) RegisterLiteralHandler(handler LiteralHandler) {
 	// Add to front so it can override existing handlers
 	i.literalHandlers = append([]LiteralHandler{handler}, i.literalHandlers...)
+
+	// Compiled code may have resolved words this handler now overrides
+	i.compileCache.clear()
 }
 
 // findLiteralWord tries to parse a string as a literal
@@ -240,14 +355,22 @@ func (i *Interpreter) findLiteralWord(name string) Word {
 
 // FindWord finds a word by name
 // Searches module stack from top to bottom, then checks literal handlers
+//
+// Modules index their words by name, so finding a defined word takes one map
+// lookup per module and doesn't allocate. Names that no module defines (such
+// as literals) are remembered until a word is added or the module stack
+// changes, so that they go straight to the literal handlers.
 func (i *Interpreter) FindWord(name string) (Word, error) {
 	// 1. Check module stack (from top to bottom)
-	for j := len(i.moduleStack) - 1; j >= 0; j-- {
-		module := i.moduleStack[j]
-		word := module.FindWord(name)
-		if word != nil {
-			return word, nil
+	if !i.missingWords.contains(name, i.stackVersion) {
+		for j := len(i.moduleStack) - 1; j >= 0; j-- {
+			module := i.moduleStack[j]
+			word := module.FindWord(name)
+			if word != nil {
+				return word, nil
+			}
 		}
+		i.missingWords.add(name, i.stackVersion)
 	}
 
 	// 2. Check literal handlers
@@ -260,21 +383,240 @@ func (i *Interpreter) FindWord(name string) (Word, error) {
 	return nil, NewUnknownWordError(name)
 }
 
+// wordMissCache remembers names not defined in any module on the module stack
+//
+// The cache is only valid for the module stack and module dictionaries it
+// was filled against, so it empties itself when either changes.
+type wordMissCache struct {
+	names             map[string]struct{}
+	dictionaryVersion uint64
+	stackVersion      uint64
+}
+
+// contains reports whether name is known not to be defined
+func (c *wordMissCache) contains(name string, stackVersion uint64) bool {
+	if c.names == nil || !c.isCurrent(stackVersion) {
+		return false
+	}
+	_, ok := c.names[name]
+	return ok
+}
+
+// add records that name is not defined
+func (c *wordMissCache) add(name string, stackVersion uint64) {
+	if c.names == nil || !c.isCurrent(stackVersion) || len(c.names) >= maxMissingWords {
+		c.names = make(map[string]struct{})
+		c.dictionaryVersion = atomic.LoadUint64(&dictionaryVersion)
+		c.stackVersion = stackVersion
+	}
+	c.names[name] = struct{}{}
+}
+
+func (c *wordMissCache) isCurrent(stackVersion uint64) bool {
+	return c.stackVersion == stackVersion && c.dictionaryVersion == atomic.LoadUint64(&dictionaryVersion)
+}
+
 // ============================================================================
 // Main Execution
 // ============================================================================
 
 // Run executes Forthic code
+//
+// Panics raised while executing words (e.g., stack underflow) are recovered
+// and returned as errors. On error, the module stack, compile state and
+// tokenizer stack are restored to what they were before Run was called; in
+// transactional mode (see SetTransactional), so is everything else.
+//
+// When code was popped from a string literal by a word in code that is
+// already running (as with INTERPRET), locations are reported relative to the
+// string literal. Other code is located from line 1, column 1.
 func (i *Interpreter) Run(code string) error {
-	tokenizer := NewTokenizer(code, nil, false)
+	return i.run(code, i.nestedCodeLocation(code))
+}
+
+// RunSource executes Forthic code, naming name as its source file
+// Every location reported for the code, including locations within code
+// strings it runs (via INTERPRET, MAP and so on), carries the name.
+func (i *Interpreter) RunSource(name string, code string) error {
+	return i.run(code, &CodeLocation{File: name, Line: 1, Column: 1})
+}
+
+// RunFile reads and executes a Forthic file
+// Locations reported for the code name the file by path.
+func (i *Interpreter) RunFile(path string) error {
+	code, err := os.ReadFile(path)
+	if err != nil {
+		return NewForthicError(fmt.Sprintf("Cannot read file: %s", path)).WithCause(err)
+	}
+	return i.RunSource(path, string(code))
+}
+
+// run executes code whose first character is at reference (nil for line 1, col 1)
+func (i *Interpreter) run(code string, reference *CodeLocation) (err error) {
+	if err := i.enterCall(i.CurrentLocation()); err != nil {
+		return err
+	}
+	defer i.exitCall()
+
+	if i.transactional && i.transaction == nil {
+		i.beginTransaction()
+		defer func() { i.endTransaction(err) }()
+	}
+
+	state := i.saveRunState()
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicToError(r, nil, i.CurrentLocation())
+		}
+		if err != nil {
+			i.restoreRunState(state)
+		}
+	}()
+
+	tokenizer := NewTokenizer(code, reference, false)
 	i.tokenizerStack = append(i.tokenizerStack, tokenizer)
 
-	err := i.runWithTokenizer(tokenizer)
+	err = i.runWithTokenizer(tokenizer)
 
 	i.tokenizerStack = i.tokenizerStack[:len(i.tokenizerStack)-1]
 	return err
 }
 
+// RunContext executes Forthic code, stopping early if ctx is cancelled
+//
+// The context is checked between word executions. When it is done, Run
+// returns a CancelledError or DeadlineExceededError with the location where
+// execution stopped. Module words can read the context via Context.
+func (i *Interpreter) RunContext(ctx context.Context, code string) error {
+	prevCtx := i.ctx
+	i.ctx = ctx
+	defer func() { i.ctx = prevCtx }()
+
+	return i.Run(code)
+}
+
+// ExecuteContext executes a word, stopping early if ctx is cancelled
+// Module words use it to run compiled code on a fork, on another goroutine
+// (see Fork). Panics raised by words are recovered and returned as errors.
+func (i *Interpreter) ExecuteContext(ctx context.Context, word Word) (err error) {
+	prevCtx := i.ctx
+	i.ctx = ctx
+	defer func() { i.ctx = prevCtx }()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicToError(r, word, i.CurrentLocation())
+		}
+	}()
+	return word.Execute(i)
+}
+
+// ScopedErrorHandler - An error handler attached to a word for a single run
+type ScopedErrorHandler struct {
+	Word     Word
+	Handler  WordErrorHandler
+	Priority int
+}
+
+// RunWithErrorHandlers executes Forthic code with extra error handlers attached
+//
+// Each handler is attached to its word for the duration of the call only,
+// scoped to this interpreter, so other interpreters sharing the word are not
+// affected. Handlers are removed when the call returns, even if it fails.
+func (i *Interpreter) RunWithErrorHandlers(code string, handlers ...ScopedErrorHandler) error {
+	for _, h := range handlers {
+		handle := h.Word.AddErrorHandlerWithOptions(h.Handler, ErrorHandlerOptions{
+			Priority: h.Priority,
+			Interp:   i,
+		})
+		defer h.Word.RemoveErrorHandler(handle)
+	}
+	return i.Run(code)
+}
+
+// Context returns the context of the current RunContext call
+// Outside of RunContext, this is context.Background().
+func (i *Interpreter) Context() context.Context {
+	return i.ctx
+}
+
+// CheckContext returns an error if the current context is done
+// Long-running module words should call this periodically.
+func (i *Interpreter) CheckContext() error {
+	return i.checkContext(i.CurrentLocation())
+}
+
+// checkContext returns an error located at loc if the current context is done
+func (i *Interpreter) checkContext(loc *CodeLocation) error {
+	// Background and TODO contexts can never be cancelled
+	if i.ctx.Done() == nil {
+		return nil
+	}
+	if err := i.ctx.Err(); err != nil {
+		return newContextError(err, loc)
+	}
+	return nil
+}
+
+// runState captures the interpreter state that Run restores on error
+type runState struct {
+	moduleStack      []*Module
+	numTokenizers    int
+	isCompiling      bool
+	isMemoDefinition bool
+	curDefinition    *DefinitionWord
+	previousToken    *Token
+}
+
+// saveRunState snapshots the state restored by restoreRunState
+func (i *Interpreter) saveRunState() *runState {
+	moduleStack := make([]*Module, len(i.moduleStack))
+	copy(moduleStack, i.moduleStack)
+	return &runState{
+		moduleStack:      moduleStack,
+		numTokenizers:    len(i.tokenizerStack),
+		isCompiling:      i.isCompiling,
+		isMemoDefinition: i.isMemoDefinition,
+		curDefinition:    i.curDefinition,
+		previousToken:    i.previousToken,
+	}
+}
+
+// restoreRunState restores a state captured by saveRunState
+func (i *Interpreter) restoreRunState(state *runState) {
+	i.restoreModuleStack(state.moduleStack)
+	i.stackVersion++
+	if len(i.tokenizerStack) > state.numTokenizers {
+		i.tokenizerStack = i.tokenizerStack[:state.numTokenizers]
+	}
+	i.isCompiling = state.isCompiling
+	i.isMemoDefinition = state.isMemoDefinition
+	i.curDefinition = state.curDefinition
+	i.previousToken = state.previousToken
+}
+
+// restoreModuleStack returns the module stack to a saved state, notifying
+// observers of each module popped and pushed to get there
+func (i *Interpreter) restoreModuleStack(saved []*Module) {
+	common := 0
+	for common < len(saved) && common < len(i.moduleStack) && saved[common] == i.moduleStack[common] {
+		common++
+	}
+	for len(i.moduleStack) > common {
+		module := i.moduleStack[len(i.moduleStack)-1]
+		i.moduleStack = i.moduleStack[:len(i.moduleStack)-1]
+		for _, o := range i.observers {
+			o.OnModulePop(module)
+		}
+	}
+	for _, module := range saved[common:] {
+		i.moduleStack = append(i.moduleStack, module)
+		for _, o := range i.observers {
+			o.OnModulePush(module)
+		}
+	}
+}
+
 // runWithTokenizer executes code using the given tokenizer
 func (i *Interpreter) runWithTokenizer(tokenizer *Tokenizer) error {
 	for {
@@ -283,6 +625,10 @@ func (i *Interpreter) runWithTokenizer(tokenizer *Tokenizer) error {
 			return err
 		}
 
+		if err := i.checkContext(token.Location); err != nil {
+			return err
+		}
+
 		err = i.handleToken(token)
 		if err != nil {
 			return err
@@ -341,10 +687,64 @@ func (i *Interpreter) handleToken(token *Token) error {
 
 // handleStringToken handles string literals
 func (i *Interpreter) handleStringToken(token *Token) error {
-	word := NewPushValueWord("<string>", token.String)
+	word := newStringLiteralWord(token.String, token.Location)
 	return i.handleWord(word, token.Location)
 }
 
+// stringLiteral is a string pushed by a string literal, and where the
+// literal's contents start
+type stringLiteral struct {
+	value    string
+	location *CodeLocation
+	slot     int // index of the stack slot the literal was pushed to
+}
+
+// pushLiteral pushes a string literal, remembering the slot it occupies
+func (i *Interpreter) pushLiteral(value string, location *CodeLocation) {
+	slot := i.stack.Length()
+	i.dropLiterals(slot)
+	i.literals = append(i.literals, stringLiteral{value: value, location: location, slot: slot})
+	i.stack.Push(value)
+}
+
+// popLiteral notes that val was popped, so that if it was pushed by a string
+// literal, code the executing word runs from it is located at the literal
+func (i *Interpreter) popLiteral(val interface{}) {
+	slot := i.stack.Length()
+	i.dropLiterals(slot + 1)
+	last := len(i.literals) - 1
+	if last < 0 || i.literals[last].slot != slot {
+		return
+	}
+	literal := i.literals[last]
+	i.literals = i.literals[:last]
+	// The slot may have been overwritten without going through StackPop
+	if str, ok := val.(string); ok && str == literal.value {
+		i.poppedLiteral = &literal
+	}
+}
+
+// dropLiterals forgets literals in slots at or above slot, which have been
+// popped or replaced
+func (i *Interpreter) dropLiterals(slot int) {
+	n := len(i.literals)
+	for n > 0 && i.literals[n-1].slot >= slot {
+		n--
+	}
+	i.literals = i.literals[:n]
+}
+
+// nestedCodeLocation returns the location of the string literal code came
+// from: the literal the executing word popped, if code is its string
+// Code that wasn't popped from a literal (built with CONCAT, say, or run by
+// Go code between runs) has no location to borrow.
+func (i *Interpreter) nestedCodeLocation(code string) *CodeLocation {
+	if i.callDepth == 0 || i.poppedLiteral == nil || i.poppedLiteral.value != code {
+		return nil
+	}
+	return i.poppedLiteral.location
+}
+
 // handleDotSymbolToken handles dot symbols
 func (i *Interpreter) handleDotSymbolToken(token *Token) error {
 	word := NewPushValueWord("<dot-symbol>", token.String)
@@ -374,10 +774,10 @@ func (i *Interpreter) handleStartModuleToken(token *Token) error {
 
 	// Module words are immediate (execute during compilation) and also compiled
 	if i.isCompiling {
-		i.curDefinition.words = append(i.curDefinition.words, word)
+		i.curDefinition.addWord(word, token.Location)
 	}
 
-	return word.Execute(i)
+	return executeWord(word, i, token.Location)
 }
 
 // handleEndModuleToken handles }
@@ -386,10 +786,10 @@ func (i *Interpreter) handleEndModuleToken(token *Token) error {
 
 	// Module words are immediate (execute during compilation) and also compiled
 	if i.isCompiling {
-		i.curDefinition.words = append(i.curDefinition.words, word)
+		i.curDefinition.addWord(word, token.Location)
 	}
 
-	return word.Execute(i)
+	return executeWord(word, i, token.Location)
 }
 
 // handleStartDefinitionToken handles :
@@ -398,6 +798,7 @@ func (i *Interpreter) handleStartDefinitionToken(token *Token) error {
 		return NewMissingSemicolonError().WithLocation(i.previousToken.Location)
 	}
 	i.curDefinition = NewDefinitionWord(token.String, nil)
+	i.curDefinition.SetLocation(token.Location)
 	i.isCompiling = true
 	i.isMemoDefinition = false
 	return nil
@@ -408,9 +809,15 @@ func (i *Interpreter) handleStartMemoToken(token *Token) error {
 	if i.isCompiling {
 		return NewMissingSemicolonError().WithLocation(i.previousToken.Location)
 	}
+	ttl, err := i.memoOptions()
+	if err != nil {
+		return err
+	}
 	i.curDefinition = NewDefinitionWord(token.String, nil)
+	i.curDefinition.SetLocation(token.Location)
 	i.isCompiling = true
 	i.isMemoDefinition = true
+	i.memoTTL = ttl
 	return nil
 }
 
@@ -420,13 +827,20 @@ func (i *Interpreter) handleEndDefinitionToken(token *Token) error {
 		return NewExtraSemicolonError().WithLocation(token.Location)
 	}
 
+	i.curDefinition.source = i.GetTokenizer().definitionSource()
+	i.curDefinition.order = atomic.AddUint64(&lastDefinitionOrder, 1)
+	module := i.CurModule()
 	if i.isMemoDefinition {
-		i.CurModule().AddMemoWords(i.curDefinition)
+		memo := module.AddMemoWords(i.curDefinition)
+		memo.SetTTL(i.memoTTL)
+		i.curDefinition.memo = memo
 	} else {
-		i.CurModule().AddWord(i.curDefinition)
+		module.AddWord(i.curDefinition)
 	}
+	module.definitions = append(module.definitions, i.curDefinition)
 
 	i.isCompiling = false
+	i.notifyDefinition(i.curDefinition, i.curDefinition.GetLocation())
 	return nil
 }
 
@@ -434,6 +848,10 @@ func (i *Interpreter) handleEndDefinitionToken(token *Token) error {
 func (i *Interpreter) handleWordToken(token *Token) error {
 	word, err := i.FindWord(token.String)
 	if err != nil {
+		if unknown, ok := err.(*UnknownWordError); ok {
+			unknown.Suggestions = i.suggestWords(unknown.Word)
+			return unknown.WithLocation(token.Location)
+		}
 		return err
 	}
 	return i.handleWord(word, token.Location)
@@ -442,11 +860,10 @@ func (i *Interpreter) handleWordToken(token *Token) error {
 // handleWord executes or compiles a word
 func (i *Interpreter) handleWord(word Word, location *CodeLocation) error {
 	if i.isCompiling {
-		word.SetLocation(location)
-		i.curDefinition.words = append(i.curDefinition.words, word)
+		i.curDefinition.addWord(word, location)
 		return nil
 	} else {
-		return word.Execute(i)
+		return executeWord(word, i, location)
 	}
 }
 
@@ -478,11 +895,15 @@ func (w *StartModuleWord) Execute(interp *Interpreter) error {
 	if module == nil {
 		// Create new module
 		module = NewModule(w.name)
+		module.SetInterp(interp)
+		module.inline = true
 		interp.CurModule().RegisterModule(w.name, w.name, module)
 
 		// If we're at app module, also register with interpreter
 		if interp.CurModule().name == "" {
-			interp.RegisterModule(module)
+			if err := interp.RegisterModule(module); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -1,6 +1,10 @@
 package forthic
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -157,6 +161,67 @@ func TestInterpreter_StackUnderflow(t *testing.T) {
 	})
 }
 
+func TestInterpreter_StackUnderflowInRun(t *testing.T) {
+	interp := NewInterpreter()
+	err := interp.Run("]")
+	assert.Error(t, err)
+
+	var underflow *StackUnderflowError
+	assert.True(t, errors.As(err, &underflow))
+	assert.NotNil(t, underflow.Location)
+	assert.Equal(t, 1, underflow.Location.Line)
+	assert.Equal(t, "]", underflow.Word)
+}
+
+func TestInterpreter_PanicInWordBecomesError(t *testing.T) {
+	interp := NewInterpreter()
+	module := NewModule("test")
+	module.AddModuleWord("BAD-CAST", func(interp *Interpreter) error {
+		_ = interp.StackPop().(string)
+		return nil
+	})
+	interp.ImportModule(module, "")
+
+	err := interp.Run("42 BAD-CAST")
+	assert.Error(t, err)
+
+	var execErr *WordExecutionError
+	assert.True(t, errors.As(err, &execErr))
+	assert.Equal(t, "BAD-CAST", execErr.Word)
+	assert.NotNil(t, execErr.Location)
+	assert.Equal(t, 4, execErr.Location.Column)
+}
+
+func TestInterpreter_StackUnderflowInDefinition(t *testing.T) {
+	interp := NewInterpreter()
+	err := interp.Run(`: BROKEN ] ; BROKEN`)
+
+	var underflow *StackUnderflowError
+	assert.True(t, errors.As(err, &underflow))
+}
+
+func TestInterpreter_StateRestoredAfterError(t *testing.T) {
+	interp := NewInterpreter()
+	err := interp.Run(`{mymodule : WORD ] ; }`)
+	assert.NoError(t, err)
+
+	err = interp.Run(`{mymodule WORD`)
+	assert.Error(t, err)
+	assert.Equal(t, 1, len(interp.moduleStack))
+	assert.Equal(t, "", interp.CurModule().GetName())
+	assert.Equal(t, 0, len(interp.tokenizerStack))
+	assert.False(t, interp.isCompiling)
+
+	err = interp.Run(`: HALF UNKNOWN_WORD`)
+	assert.Error(t, err)
+	assert.False(t, interp.isCompiling)
+
+	// Interpreter is still usable
+	err = interp.Run(`: PUSH_42 42 ; PUSH_42`)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), interp.StackPop())
+}
+
 func TestInterpreter_MissingSemicolon(t *testing.T) {
 	interp := NewInterpreter()
 	err := interp.Run(`: WORD`)
@@ -170,3 +235,192 @@ func TestInterpreter_ExtraSemicolon(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "Extra semicolon")
 }
+
+// ============================================================================
+// Source Files
+// ============================================================================
+
+// newInterpretModule returns a module with an INTERPRET word that runs a
+// code string, and a COMPILE-RUN word that compiles and executes one
+func newInterpretModule() *Module {
+	module := NewModule("interpret", "")
+	module.AddModuleWord("INTERPRET", func(interp *Interpreter) error {
+		return interp.Run(interp.StackPop().(string))
+	})
+	module.AddModuleWord("COMPILE-RUN", func(interp *Interpreter) error {
+		word, err := interp.Compile(interp.StackPop().(string))
+		if err != nil {
+			return err
+		}
+		return word.Execute(interp)
+	})
+	module.AddModuleWord("POP", func(interp *Interpreter) error {
+		interp.StackPop()
+		return nil
+	})
+	module.AddModuleWord("CONCAT", func(interp *Interpreter) error {
+		b := interp.StackPop().(string)
+		interp.StackPush(interp.StackPop().(string) + b)
+		return nil
+	})
+	return module
+}
+
+func TestInterpreter_RunSourceNamesFile(t *testing.T) {
+	interp := NewInterpreter()
+	err := interp.RunSource("main.forthic", "1 2\n  NOPE")
+
+	var unknown *UnknownWordError
+	assert.True(t, errors.As(err, &unknown))
+	assert.Equal(t, "main.forthic", unknown.Location.File)
+	assert.Equal(t, 2, unknown.Location.Line)
+	assert.Equal(t, 3, unknown.Location.Column)
+	assert.Contains(t, err.Error(), "main.forthic:2:3")
+}
+
+func TestInterpreter_RunFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "script.forthic")
+	assert.NoError(t, os.WriteFile(path, []byte(": DOUBLE 2 ;\nDOUBLE\nNOPE"), 0644))
+
+	interp := NewInterpreter()
+	err := interp.RunFile(path)
+
+	var unknown *UnknownWordError
+	assert.True(t, errors.As(err, &unknown))
+	assert.Equal(t, path, unknown.Location.File)
+	assert.Equal(t, 3, unknown.Location.Line)
+	assert.Equal(t, int64(2), interp.StackPop())
+}
+
+func TestInterpreter_RunFileMissing(t *testing.T) {
+	interp := NewInterpreter()
+	err := interp.RunFile(filepath.Join(t.TempDir(), "missing.forthic"))
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
+
+func TestInterpreter_NestedCodeLocations(t *testing.T) {
+	interp := NewInterpreter(newInterpretModule())
+	err := interp.RunSource("main.forthic", "1\n  \"2\n  NOPE\" INTERPRET")
+
+	var unknown *UnknownWordError
+	assert.True(t, errors.As(err, &unknown))
+	assert.Equal(t, "main.forthic", unknown.Location.File)
+	assert.Equal(t, 3, unknown.Location.Line)
+	assert.Equal(t, 3, unknown.Location.Column)
+
+	// Locations on the first line of the string are offset by the literal's column
+	err = interp.RunSource("main.forthic", "1 2 'NOPE' COMPILE-RUN")
+	assert.True(t, errors.As(err, &unknown))
+	assert.Equal(t, 1, unknown.Location.Line)
+	assert.Equal(t, 6, unknown.Location.Column)
+}
+
+func TestInterpreter_NestedCodeLocationComesFromItsLiteral(t *testing.T) {
+	interp := NewInterpreter(newInterpretModule())
+
+	// A later literal with the same contents doesn't claim RUNIT's code
+	err := interp.RunSource("main.forthic", ": RUNIT\n  \"NOPE\" INTERPRET ;\n\n  \"NOPE\" POP RUNIT")
+	var unknown *UnknownWordError
+	assert.True(t, errors.As(err, &unknown), "got %v", err)
+	assert.Equal(t, "main.forthic", unknown.Location.File)
+	assert.Equal(t, 2, unknown.Location.Line)
+	assert.Equal(t, 4, unknown.Location.Column)
+
+	// Code built from strings has no literal to be located at
+	err = interp.RunSource("main.forthic", "\"NOPE\" POP\n\"NO\" \"PE\" CONCAT INTERPRET")
+	assert.True(t, errors.As(err, &unknown), "got %v", err)
+	assert.Equal(t, "", unknown.Location.File)
+	assert.Equal(t, 1, unknown.Location.Line)
+	assert.Equal(t, 1, unknown.Location.Column)
+}
+
+func TestInterpreter_TopLevelRunIgnoresStringLocations(t *testing.T) {
+	interp := NewInterpreter()
+	assert.NoError(t, interp.Run(`"NOPE"`))
+
+	err := interp.Run("NOPE")
+	var unknown *UnknownWordError
+	assert.True(t, errors.As(err, &unknown))
+	assert.Equal(t, 1, unknown.Location.Column)
+	assert.Equal(t, "", unknown.Location.File)
+}
+
+// ============================================================================
+// Word Lookup
+// ============================================================================
+
+func TestInterpreter_FindWordDoesNotAllocate(t *testing.T) {
+	interp := NewInterpreter()
+	assert.NoError(t, interp.Run(`: DOUBLE 2 ;`))
+	interp.GetAppModule().AddVariable("counter", 0)
+	for j := 0; j < 20; j++ {
+		interp.CurModule().AddWord(NewPushValueWord(fmt.Sprintf("FILLER-%d", j), j))
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := interp.FindWord("DOUBLE"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := interp.FindWord("counter"); err != nil {
+			t.Fatal(err)
+		}
+	})
+	assert.Equal(t, 0.0, allocs)
+}
+
+func TestInterpreter_MissingWordCacheInvalidation(t *testing.T) {
+	interp := NewInterpreter()
+
+	// "42" is a literal until a word named 42 is defined
+	assert.NoError(t, interp.Run(`42`))
+	assert.Equal(t, int64(42), interp.StackPop())
+	assert.NoError(t, interp.Run(`: 42 "forty-two" ; 42`))
+	assert.Equal(t, "forty-two", interp.StackPop())
+
+	// Words only defined in another module become visible when it's pushed
+	assert.NoError(t, interp.Run(`{mymodule : SECRET 7 ; }`))
+	err := interp.Run(`SECRET`)
+	assert.True(t, errors.Is(err, ErrUnknownWord))
+	assert.NoError(t, interp.Run(`{mymodule SECRET }`))
+	assert.Equal(t, int64(7), interp.StackPop())
+}
+
+func TestInterpreter_UserLiteralHandlerTakesPrecedence(t *testing.T) {
+	interp := NewInterpreter()
+	assert.NoError(t, interp.Run(`42`))
+	assert.Equal(t, int64(42), interp.StackPop())
+
+	interp.RegisterLiteralHandler(func(str string) (interface{}, bool) {
+		if str == "42" {
+			return "answer", true
+		}
+		return nil, false
+	})
+	assert.NoError(t, interp.Run(`42`))
+	assert.Equal(t, "answer", interp.StackPop())
+}
+
+func BenchmarkInterpreter_FindWord(b *testing.B) {
+	interp := NewInterpreter()
+	for j := 0; j < 1000; j++ {
+		interp.GetAppModule().AddWord(NewPushValueWord(fmt.Sprintf("WORD-%d", j), j))
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if _, err := interp.FindWord("WORD-0"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkInterpreter_FindLiteral(b *testing.B) {
+	interp := NewInterpreter()
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		if _, err := interp.FindWord("12:30"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package forthic
+
+import "sync/atomic"
+
+// Limit names reported by ResourceLimitError
+const (
+	LIMIT_INSTRUCTIONS    = "max_instructions"
+	LIMIT_STACK_DEPTH     = "max_stack_depth"
+	LIMIT_CALL_DEPTH      = "max_call_depth"
+	LIMIT_COLLECTION_SIZE = "max_collection_size"
+)
+
+// DefaultMaxCallDepth bounds nested Run/definition calls so that runaway
+// recursion returns an error instead of exhausting the Go stack
+const DefaultMaxCallDepth = 10000
+
+// Limits - Resource limits for an Interpreter
+//
+// A zero value for any field means that resource is unlimited. Exceeding a
+// limit stops execution with a ResourceLimitError.
+type Limits struct {
+	MaxInstructions   int // Word executions per top-level Run, including forks made during it
+	MaxStackDepth     int // Length of the data stack
+	MaxCallDepth      int // Depth of nested Run and definition calls
+	MaxCollectionSize int // Size of collections built by words like <REPEAT and FLATTEN
+}
+
+// DefaultLimits returns the limits a new Interpreter starts with
+func DefaultLimits() Limits {
+	return Limits{MaxCallDepth: DefaultMaxCallDepth}
+}
+
+// SetLimits sets the interpreter's resource limits
+func (i *Interpreter) SetLimits(limits Limits) {
+	i.limits = limits
+}
+
+// GetLimits returns the interpreter's resource limits
+func (i *Interpreter) GetLimits() Limits {
+	return i.limits
+}
+
+// CheckCollectionSize returns an error if size exceeds MaxCollectionSize
+// Module words that build collections should call this before allocating.
+func (i *Interpreter) CheckCollectionSize(size int) error {
+	max := i.limits.MaxCollectionSize
+	if max > 0 && size > max {
+		return newResourceLimitErrorAt(LIMIT_COLLECTION_SIZE, max, i.CurrentLocation())
+	}
+	return nil
+}
+
+// countInstruction counts a word execution against MaxInstructions
+func (i *Interpreter) countInstruction(loc *CodeLocation) error {
+	count := i.budget.instructions.Add(1)
+	max := i.limits.MaxInstructions
+	if max > 0 && count > int64(max) {
+		return newResourceLimitErrorAt(LIMIT_INSTRUCTIONS, max, loc)
+	}
+	return nil
+}
+
+// checkStackDepth returns an error if the stack is longer than MaxStackDepth
+func (i *Interpreter) checkStackDepth(loc *CodeLocation) error {
+	max := i.limits.MaxStackDepth
+	if max > 0 && i.stack.Length() > max {
+		return newResourceLimitErrorAt(LIMIT_STACK_DEPTH, max, loc)
+	}
+	return nil
+}
+
+// runBudget counts the word executions of a top-level run
+// Forks made during the run share it, so their work counts against the run.
+type runBudget struct {
+	instructions atomic.Int64
+	done         atomic.Bool // the run has finished
+}
+
+// enterCall records entry into a Run or definition call
+// Each successful enterCall must be paired with exitCall.
+func (i *Interpreter) enterCall(loc *CodeLocation) error {
+	if i.callDepth == i.baseCallDepth && i.budget.done.Load() {
+		// This is a fork, and the run it was made during has finished
+		i.callDepth = 0
+		i.baseCallDepth = 0
+	}
+
+	max := i.limits.MaxCallDepth
+	if max > 0 && i.callDepth >= max {
+		return newResourceLimitErrorAt(LIMIT_CALL_DEPTH, max, loc)
+	}
+	if i.callDepth == 0 {
+		i.budget = &runBudget{}
+	}
+	i.callDepth++
+	return nil
+}
+
+// exitCall records exit from a Run or definition call
+func (i *Interpreter) exitCall() {
+	i.callDepth--
+	if i.callDepth == 0 {
+		i.budget.done.Store(true)
+	}
+}
+
+func newResourceLimitErrorAt(limit string, max int, loc *CodeLocation) *ResourceLimitError {
+	return NewResourceLimitError(limit, max).WithLocation(loc)
+}
@@ -0,0 +1,86 @@
+package forthic
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimits_Defaults(t *testing.T) {
+	interp := NewInterpreter()
+	assert.Equal(t, DefaultLimits(), interp.GetLimits())
+	assert.Equal(t, DefaultMaxCallDepth, interp.GetLimits().MaxCallDepth)
+}
+
+func TestLimits_MaxInstructions(t *testing.T) {
+	interp := NewInterpreter()
+	interp.SetLimits(Limits{MaxInstructions: 3})
+
+	assert.NoError(t, interp.Run(`1 2 3`))
+
+	// The budget is per top-level Run
+	err := interp.Run(`1 2 3 4`)
+	var limitErr *ResourceLimitError
+	assert.True(t, errors.As(err, &limitErr))
+	assert.Equal(t, LIMIT_INSTRUCTIONS, limitErr.Limit)
+	assert.Equal(t, 3, limitErr.Max)
+	assert.Equal(t, 7, limitErr.Location.Column)
+}
+
+func TestLimits_MaxInstructionsCountsDefinitionBodies(t *testing.T) {
+	interp := NewInterpreter()
+	assert.NoError(t, interp.Run(`: THREE 1 2 3 ;`))
+	interp.SetLimits(Limits{MaxInstructions: 3})
+
+	err := interp.Run(`THREE`)
+	var limitErr *ResourceLimitError
+	assert.True(t, errors.As(err, &limitErr))
+}
+
+func TestLimits_MaxStackDepth(t *testing.T) {
+	interp := NewInterpreter()
+	interp.SetLimits(Limits{MaxStackDepth: 2})
+
+	err := interp.Run(`1 2 3`)
+	var limitErr *ResourceLimitError
+	assert.True(t, errors.As(err, &limitErr))
+	assert.Equal(t, LIMIT_STACK_DEPTH, limitErr.Limit)
+}
+
+func TestLimits_MaxCallDepth(t *testing.T) {
+	interp := NewInterpreter()
+	interp.SetLimits(Limits{MaxCallDepth: 50})
+
+	module := NewModule("test")
+	module.AddModuleWord("INTERPRET", func(interp *Interpreter) error {
+		return interp.Run(interp.StackPop().(string))
+	})
+	interp.ImportModule(module, "")
+
+	err := interp.Run(`: RECURSE "RECURSE" INTERPRET ; RECURSE`)
+	var limitErr *ResourceLimitError
+	assert.True(t, errors.As(err, &limitErr))
+	assert.Equal(t, LIMIT_CALL_DEPTH, limitErr.Limit)
+	assert.Equal(t, 0, interp.callDepth)
+
+	// Interpreter is usable afterwards
+	assert.NoError(t, interp.Run(`1`))
+}
+
+func TestLimits_ErrorHandlersCannotSwallowLimits(t *testing.T) {
+	interp := NewInterpreter()
+	interp.SetLimits(Limits{MaxCollectionSize: 1})
+
+	word := NewModuleWord("BIG", func(interp *Interpreter) error {
+		return interp.CheckCollectionSize(2)
+	})
+	word.AddErrorHandler(func(err error, w Word, i *Interpreter) error {
+		return nil
+	})
+
+	err := word.Execute(interp)
+	var limitErr *ResourceLimitError
+	assert.True(t, errors.As(err, &limitErr))
+	assert.Equal(t, LIMIT_COLLECTION_SIZE, limitErr.Limit)
+}
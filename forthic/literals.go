@@ -7,6 +7,13 @@ import (
 	"time"
 )
 
+// Literal patterns, compiled once
+var (
+	timeLiteralRe = regexp.MustCompile(`^(\d{1,2}):(\d{2})(?:\s*(AM|PM))?$`)
+	dateLiteralRe = regexp.MustCompile(`^(\d{4}|YYYY)-(\d{2}|MM)-(\d{2}|DD)$`)
+	zoneOffsetRe  = regexp.MustCompile(`[+-]\d{2}:\d{2}$`)
+)
+
 // ============================================================================
 // Boolean Literals
 // ============================================================================
@@ -63,8 +70,7 @@ func ToInt(str string) (interface{}, bool) {
 // ToTime parses time literals: 9:00, 11:30 PM, 22:15
 func ToTime(str string) (interface{}, bool) {
 	// Pattern: HH:MM or HH:MM AM/PM
-	re := regexp.MustCompile(`^(\d{1,2}):(\d{2})(?:\s*(AM|PM))?$`)
-	match := re.FindStringSubmatch(str)
+	match := timeLiteralRe.FindStringSubmatch(str)
 	if match == nil {
 		return nil, false
 	}
@@ -106,8 +112,7 @@ func ToTime(str string) (interface{}, bool) {
 func ToLiteralDate(timezone *time.Location) LiteralHandler {
 	return func(str string) (interface{}, bool) {
 		// Pattern: YYYY-MM-DD or wildcards (YYYY, MM, DD)
-		re := regexp.MustCompile(`^(\d{4}|YYYY)-(\d{2}|MM)-(\d{2}|DD)$`)
-		match := re.FindStringSubmatch(str)
+		match := dateLiteralRe.FindStringSubmatch(str)
 		if match == nil {
 			return nil, false
 		}
@@ -210,8 +215,7 @@ func ToZonedDateTime(timezone *time.Location) LiteralHandler {
 		}
 
 		// Handle explicit timezone offset (+05:00, -05:00)
-		offsetRe := regexp.MustCompile(`[+-]\d{2}:\d{2}$`)
-		if offsetRe.MatchString(str) {
+		if zoneOffsetRe.MatchString(str) {
 			t, err := time.Parse(time.RFC3339, str)
 			if err != nil {
 				return nil, false
@@ -0,0 +1,368 @@
+package forthic
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// Memo Words
+// ============================================================================
+
+// ModuleMemoWord - Memoized word that caches its result
+//
+// A memo word is defined with "@:". The first time it runs, its definition
+// is executed and the value it leaves on the stack is cached; later runs push
+// the cached value. NAME! recomputes the value and NAME!@ recomputes and
+// pushes it.
+//
+// A memo may have a TTL, after which the cached value expires and is
+// recomputed on next use:
+//
+//	[.ttl 300] ~> @: RATES  "rates.json" LOAD-RATES ;
+//
+// The TTL is in seconds, or a Go duration string like "5m". Memo words may
+// be shared by interpreters running concurrently. Computing a value is
+// single-flight: callers that need the value while it is being computed
+// wait for that computation rather than starting their own.
+type ModuleMemoWord struct {
+	*BaseWord
+	word Word
+
+	mu         sync.Mutex
+	hasValue   bool
+	value      interface{}
+	computedAt time.Time
+	ttl        time.Duration
+	call       *memoCall // computation in progress, if any
+	generation uint64    // incremented by Invalidate
+	clock      func() time.Time
+}
+
+// memoCall is a computation of a memo's value that other callers can wait on
+type memoCall struct {
+	interp *Interpreter
+	done   chan struct{}
+	value  interface{}
+	err    error
+}
+
+// NewModuleMemoWord creates a new ModuleMemoWord
+func NewModuleMemoWord(word Word) *ModuleMemoWord {
+	return &ModuleMemoWord{
+		BaseWord: NewBaseWord(word.GetName()),
+		word:     word,
+		clock:    time.Now,
+	}
+}
+
+// SetTTL sets how long a computed value is cached; 0 means forever
+func (w *ModuleMemoWord) SetTTL(ttl time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.ttl = ttl
+}
+
+// TTL returns how long a computed value is cached; 0 means forever
+func (w *ModuleMemoWord) TTL() time.Duration {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.ttl
+}
+
+// Invalidate discards the cached value, so the next use recomputes it
+func (w *ModuleMemoWord) Invalidate() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.hasValue = false
+	w.value = nil
+	w.generation++
+}
+
+// Refresh recomputes the cached value
+func (w *ModuleMemoWord) Refresh(interp *Interpreter) error {
+	_, err := w.load(interp, true)
+	return err
+}
+
+func (w *ModuleMemoWord) Execute(interp *Interpreter) error {
+	value, err := w.load(interp, false)
+	if err != nil {
+		return err
+	}
+	interp.StackPush(value)
+	return nil
+}
+
+// load returns the cached value, computing it if missing, expired or forced
+//
+// A caller waiting for another interpreter's computation stops waiting when
+// its own context is done. If that computation is cancelled instead, the
+// waiter computes the value itself (or waits for another caller that is),
+// since another interpreter's cancellation says nothing about the value.
+func (w *ModuleMemoWord) load(interp *Interpreter, force bool) (interface{}, error) {
+	for {
+		w.mu.Lock()
+		if !force && w.hasValue && !w.expired() {
+			value := w.value
+			w.mu.Unlock()
+			return value, nil
+		}
+		call := w.call
+		if call == nil {
+			break
+		}
+		w.mu.Unlock()
+		if call.interp == interp {
+			// An interpreter runs on one goroutine, so this is the memo's
+			// own definition using the memo
+			return nil, NewForthicError(fmt.Sprintf("Memo %s depends on itself", w.name))
+		}
+		select {
+		case <-call.done:
+		case <-interp.ctx.Done():
+			return nil, interp.CheckContext()
+		}
+		if !isContextError(call.err) {
+			return call.value, cloneError(call.err, w.name)
+		}
+	}
+
+	// w.mu is held
+	call := &memoCall{interp: interp, done: make(chan struct{})}
+	generation := w.generation
+	w.call = call
+	w.mu.Unlock()
+
+	completed := false
+	defer func() {
+		w.mu.Lock()
+		if !completed {
+			// The definition panicked; the panic continues in this goroutine
+			call.err = NewForthicError(fmt.Sprintf("Computing memo %s failed", w.name))
+		}
+		// A value computed before Invalidate was called isn't cached
+		if call.err == nil && w.generation == generation {
+			w.value = call.value
+			w.hasValue = true
+			w.computedAt = w.clock()
+		}
+		w.call = nil
+		w.mu.Unlock()
+		close(call.done)
+	}()
+
+	if call.err = w.word.Execute(interp); call.err == nil {
+		call.value = interp.StackPop()
+	}
+	completed = true
+	// Each caller gets its own copy of the error, since callers add stack
+	// frames to the errors they get back
+	return call.value, cloneError(call.err, w.name)
+}
+
+// expired returns true if the cached value has outlived its TTL
+// Must be called with w.mu held.
+func (w *ModuleMemoWord) expired() bool {
+	return w.ttl > 0 && w.clock().Sub(w.computedAt) >= w.ttl
+}
+
+// Status returns the memo's cache status
+func (w *ModuleMemoWord) Status() MemoStatus {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	status := MemoStatus{
+		Name:       w.name,
+		Cached:     w.hasValue,
+		TTL:        w.ttl,
+		Refreshing: w.call != nil,
+	}
+	if w.hasValue {
+		status.ComputedAt = w.computedAt
+		status.Expired = w.expired()
+	}
+	return status
+}
+
+// ModuleMemoBangWord - Forces refresh of a memoized word
+type ModuleMemoBangWord struct {
+	*BaseWord
+	memoWord *ModuleMemoWord
+}
+
+// NewModuleMemoBangWord creates a new ModuleMemoBangWord
+func NewModuleMemoBangWord(memoWord *ModuleMemoWord) *ModuleMemoBangWord {
+	return &ModuleMemoBangWord{
+		BaseWord: NewBaseWord(memoWord.GetName() + "!"),
+		memoWord: memoWord,
+	}
+}
+
+func (w *ModuleMemoBangWord) Execute(interp *Interpreter) error {
+	return w.memoWord.Refresh(interp)
+}
+
+// ModuleMemoBangAtWord - Refreshes a memoized word and returns its value
+type ModuleMemoBangAtWord struct {
+	*BaseWord
+	memoWord *ModuleMemoWord
+}
+
+// NewModuleMemoBangAtWord creates a new ModuleMemoBangAtWord
+func NewModuleMemoBangAtWord(memoWord *ModuleMemoWord) *ModuleMemoBangAtWord {
+	return &ModuleMemoBangAtWord{
+		BaseWord: NewBaseWord(memoWord.GetName() + "!@"),
+		memoWord: memoWord,
+	}
+}
+
+func (w *ModuleMemoBangAtWord) Execute(interp *Interpreter) error {
+	value, err := w.memoWord.load(interp, true)
+	if err != nil {
+		return err
+	}
+	interp.StackPush(value)
+	return nil
+}
+
+// ============================================================================
+// Memo Options
+// ============================================================================
+
+// memoTTLOption converts the .ttl option of a memo definition to a duration
+// Numbers are seconds; strings are Go durations like "90s" or "5m".
+func memoTTLOption(value interface{}) (time.Duration, error) {
+	var ttl time.Duration
+	switch v := value.(type) {
+	case int64:
+		ttl = time.Duration(v) * time.Second
+	case int:
+		ttl = time.Duration(v) * time.Second
+	case float64:
+		ttl = time.Duration(v * float64(time.Second))
+	case string:
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			return 0, NewForthicError(fmt.Sprintf("Invalid memo ttl: %q", v)).WithCause(err)
+		}
+		ttl = parsed
+	default:
+		return 0, NewForthicError(fmt.Sprintf("Invalid memo ttl: %v", value))
+	}
+	if ttl < 0 {
+		return 0, NewForthicError(fmt.Sprintf("Invalid memo ttl: %v", value))
+	}
+	return ttl, nil
+}
+
+// memoOptions pops WordOptions given to "@:", if any, and returns the TTL
+// Options are only taken when given directly, as in "[.ttl 60] ~> @: NAME";
+// options left on the stack by earlier code are data, not memo options.
+func (i *Interpreter) memoOptions() (time.Duration, error) {
+	previous := i.previousToken
+	if previous == nil || previous.Type != TOKEN_WORD || previous.String != "~>" || i.stack.Length() == 0 {
+		return 0, nil
+	}
+	opts, ok := i.StackPeek().(*WordOptions)
+	if !ok {
+		return 0, nil
+	}
+	i.StackPop()
+
+	var ttl time.Duration
+	for _, key := range opts.Keys() {
+		if key != "ttl" {
+			return 0, NewForthicError(fmt.Sprintf("Unknown memo option: .%s", key))
+		}
+		parsed, err := memoTTLOption(opts.Get(key))
+		if err != nil {
+			return 0, err
+		}
+		ttl = parsed
+	}
+	return ttl, nil
+}
+
+// ============================================================================
+// Memo Status
+// ============================================================================
+
+// MemoStatus - The cache status of a memo word
+type MemoStatus struct {
+	Name       string
+	Module     string        // Module defining the memo ("" for the app module)
+	Cached     bool          // A value is cached
+	ComputedAt time.Time     // When the cached value was computed
+	TTL        time.Duration // How long values are cached; 0 means forever
+	Expired    bool          // The cached value has outlived its TTL
+	Refreshing bool          // The value is being computed
+}
+
+// Memos returns the status of the memo words in the interpreter's modules
+// Memos are listed by module, then name. Redefined memos are listed once.
+func (i *Interpreter) Memos() []MemoStatus {
+	result := make([]MemoStatus, 0)
+	seen := make(map[*ModuleMemoWord]bool)
+	i.eachModule(func(module *Module) {
+		for _, name := range module.WordNames() {
+			memo, ok := module.FindDictionaryWord(name).(*ModuleMemoWord)
+			if !ok || seen[memo] {
+				continue
+			}
+			seen[memo] = true
+			status := memo.Status()
+			status.Module = module.name
+			result = append(result, status)
+		}
+	})
+	sort.SliceStable(result, func(a, b int) bool {
+		if result[a].Module != result[b].Module {
+			return result[a].Module < result[b].Module
+		}
+		return result[a].Name < result[b].Name
+	})
+	return result
+}
+
+// InvalidateMemos discards the cached values of every memo word in the
+// interpreter's modules, including memos that have since been redefined
+func (i *Interpreter) InvalidateMemos() {
+	i.eachModule(func(module *Module) {
+		for _, word := range module.words {
+			if memo, ok := word.(*ModuleMemoWord); ok {
+				memo.Invalidate()
+			}
+		}
+	})
+}
+
+// eachModule calls fn once for each registered module, the app module and
+// each module they use
+// Registered modules come first, so memos imported into the app module are
+// reported under the module that defines them.
+func (i *Interpreter) eachModule(fn func(*Module)) {
+	visited := make(map[*Module]bool)
+	var visit func(module *Module)
+	visit = func(module *Module) {
+		if module == nil || visited[module] {
+			return
+		}
+		visited[module] = true
+		fn(module)
+		for _, child := range module.modules {
+			visit(child)
+		}
+	}
+
+	names := make([]string, 0, len(i.registeredMods))
+	for name := range i.registeredMods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		visit(i.registeredMods[name])
+	}
+	visit(i.appModule)
+}
@@ -0,0 +1,355 @@
+package forthic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCountingMemoModule returns a module with a memo, RATE, whose definition
+// counts its runs and waits for release before pushing the count
+func newCountingMemoModule(release <-chan struct{}) (*Module, *int64) {
+	var runs int64
+	module := NewModule("rates", `@: RATE  COMPUTE ;`)
+	module.AddModuleWord("COMPUTE", func(interp *Interpreter) error {
+		n := atomic.AddInt64(&runs, 1)
+		if release != nil {
+			<-release
+		}
+		interp.StackPush(n)
+		return nil
+	})
+	module.AddExportable([]string{"RATE", "RATE!", "RATE!@"})
+	return module, &runs
+}
+
+// findMemo returns the memo word named name in the interpreter's app module
+func findMemo(t *testing.T, interp *Interpreter, name string) *ModuleMemoWord {
+	memo, ok := interp.GetAppModule().FindWord(name).(*ModuleMemoWord)
+	require.True(t, ok)
+	return memo
+}
+
+func TestMemo_CachesValue(t *testing.T) {
+	module, runs := newCountingMemoModule(nil)
+	interp := NewInterpreter()
+	require.NoError(t, interp.ImportModule(module, ""))
+
+	require.NoError(t, interp.Run(`RATE RATE RATE! RATE RATE!@`))
+	assert.Equal(t, []interface{}{int64(1), int64(1), int64(2), int64(3)}, interp.GetStack().Items())
+	assert.Equal(t, int64(3), *runs)
+}
+
+func TestMemo_TTL(t *testing.T) {
+	module, runs := newCountingMemoModule(nil)
+	interp := NewInterpreter()
+	require.NoError(t, interp.ImportModule(module, ""))
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	memo := findMemo(t, interp, "RATE")
+	memo.clock = func() time.Time { return now }
+	memo.SetTTL(time.Minute)
+	assert.Equal(t, time.Minute, memo.TTL())
+
+	require.NoError(t, interp.Run(`RATE`))
+	now = now.Add(59 * time.Second)
+	require.NoError(t, interp.Run(`RATE`))
+	assert.False(t, memo.Status().Expired)
+
+	now = now.Add(time.Second)
+	assert.True(t, memo.Status().Expired)
+	require.NoError(t, interp.Run(`RATE`))
+	assert.Equal(t, []interface{}{int64(1), int64(1), int64(2)}, interp.GetStack().Items())
+	assert.Equal(t, int64(2), *runs)
+	assert.Equal(t, now, memo.Status().ComputedAt)
+}
+
+// newOptionsModule returns a module with ~>, which turns an array into
+// WordOptions as the core module's ~> does
+func newOptionsModule() *Module {
+	module := NewModule("options")
+	module.AddModuleWord("~>", func(interp *Interpreter) error {
+		opts, err := NewWordOptions(interp.StackPop())
+		if err != nil {
+			return err
+		}
+		interp.StackPush(opts)
+		return nil
+	})
+	return module
+}
+
+func TestMemo_TTLOption(t *testing.T) {
+	tests := []struct {
+		options  string
+		expected time.Duration
+	}{
+		{`[.ttl 90]`, 90 * time.Second},
+		{`[.ttl 1.5]`, 1500 * time.Millisecond},
+		{`[.ttl "5m"]`, 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		interp := NewInterpreter(newOptionsModule())
+		require.NoError(t, interp.Run(tt.options+` ~> @: M 1 ;`))
+		assert.Equal(t, tt.expected, findMemo(t, interp, "M").TTL())
+		assert.Equal(t, 0, interp.GetStack().Length())
+	}
+
+	interp := NewInterpreter(newOptionsModule())
+	assert.ErrorContains(t, interp.Run(`[.ttl "soon"] ~> @: M 1 ;`), `Invalid memo ttl: "soon"`)
+}
+
+func TestMemo_OnlyTakesOptionsGivenDirectly(t *testing.T) {
+	interp := NewInterpreter(newOptionsModule())
+	require.NoError(t, interp.Run(`[.ttl 90] ~> : NOTHING ; @: M 1 ;`))
+	assert.Equal(t, time.Duration(0), findMemo(t, interp, "M").TTL())
+	require.Equal(t, 1, interp.GetStack().Length())
+
+	// Options pushed by Go code are data too
+	interp = NewInterpreter(newOptionsModule())
+	opts, err := NewWordOptions([]interface{}{"ttl", int64(90)})
+	require.NoError(t, err)
+	interp.StackPush(opts)
+	require.NoError(t, interp.Run(`@: M 1 ;`))
+	assert.Equal(t, time.Duration(0), findMemo(t, interp, "M").TTL())
+	assert.Equal(t, []interface{}{opts}, interp.GetStack().Items())
+}
+
+func TestMemo_InvalidateMemos(t *testing.T) {
+	module, runs := newCountingMemoModule(nil)
+	interp := NewInterpreter()
+	require.NoError(t, interp.ImportModule(module, "r"))
+	require.NoError(t, interp.Run(`@: LOCAL 7 ; r.RATE LOCAL`))
+
+	memos := interp.Memos()
+	require.Len(t, memos, 2)
+	assert.Equal(t, "LOCAL", memos[0].Name)
+	assert.Equal(t, "", memos[0].Module)
+	assert.Equal(t, "RATE", memos[1].Name)
+	assert.Equal(t, "rates", memos[1].Module)
+	assert.True(t, memos[0].Cached)
+	assert.True(t, memos[1].Cached)
+
+	interp.InvalidateMemos()
+	for _, status := range interp.Memos() {
+		assert.False(t, status.Cached)
+	}
+
+	require.NoError(t, interp.Run(`r.RATE`))
+	assert.Equal(t, int64(2), *runs)
+}
+
+func TestMemo_SingleFlight(t *testing.T) {
+	release := make(chan struct{})
+	module, runs := newCountingMemoModule(release)
+
+	// Interpreters sharing the module share its memo
+	interps := make([]*Interpreter, 8)
+	for j := range interps {
+		interps[j] = NewInterpreter()
+		require.NoError(t, interps[j].ImportModule(module, ""))
+	}
+	memo := findMemo(t, interps[0], "RATE")
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(interps))
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		errs[0] = interps[0].Run(`RATE`)
+	}()
+	require.Eventually(t, func() bool { return memo.Status().Refreshing }, time.Second, time.Millisecond)
+
+	for j := 1; j < len(interps); j++ {
+		wg.Add(1)
+		go func(j int) {
+			defer wg.Done()
+			errs[j] = interps[j].Run(`RATE`)
+		}(j)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int64(1), atomic.LoadInt64(runs))
+	for j, interp := range interps {
+		require.NoError(t, errs[j])
+		assert.Equal(t, []interface{}{int64(1)}, interp.GetStack().Items())
+	}
+	assert.False(t, memo.Status().Refreshing)
+}
+
+func TestMemo_InvalidateDuringRefresh(t *testing.T) {
+	release := make(chan struct{})
+	module, _ := newCountingMemoModule(release)
+	interp := NewInterpreter()
+	require.NoError(t, interp.ImportModule(module, ""))
+	memo := findMemo(t, interp, "RATE")
+
+	done := make(chan error)
+	go func() { done <- interp.Run(`RATE`) }()
+	require.Eventually(t, func() bool { return memo.Status().Refreshing }, time.Second, time.Millisecond)
+
+	memo.Invalidate()
+	close(release)
+	require.NoError(t, <-done)
+
+	// The caller got the value, but it wasn't cached
+	assert.Equal(t, []interface{}{int64(1)}, interp.GetStack().Items())
+	assert.False(t, memo.Status().Cached)
+}
+
+func TestMemo_Errors(t *testing.T) {
+	failure := errors.New("no rates")
+	module := NewModule("failing", `@: RATE  FETCH ;`)
+	module.AddModuleWord("FETCH", func(interp *Interpreter) error {
+		return failure
+	})
+	module.AddExportable([]string{"RATE"})
+	interp := NewInterpreter()
+	require.NoError(t, interp.ImportModule(module, ""))
+
+	err := interp.Run(`RATE`)
+	assert.True(t, errors.Is(err, failure))
+	assert.False(t, findMemo(t, interp, "RATE").Status().Cached)
+}
+
+func TestMemo_DependsOnItself(t *testing.T) {
+	module := NewModule("self", `@: SELF  "SELF" RUN ;`)
+	module.AddModuleWord("RUN", func(interp *Interpreter) error {
+		return interp.Run(interp.StackPop().(string))
+	})
+	module.AddExportable([]string{"SELF"})
+	interp := NewInterpreter()
+	require.NoError(t, interp.ImportModule(module, ""))
+
+	err := interp.Run(`SELF`)
+	assert.ErrorContains(t, err, "Memo SELF depends on itself")
+	assert.False(t, findMemo(t, interp, "SELF").Status().Refreshing)
+}
+
+func TestMemo_ConcurrentFailures(t *testing.T) {
+	release := make(chan struct{})
+	module := NewModule("failing", `@: RATE  FETCH ;`)
+	module.AddModuleWord("FETCH", func(interp *Interpreter) error {
+		<-release
+		return errors.New("no rates")
+	})
+	module.AddExportable([]string{"RATE"})
+	interp := NewInterpreter()
+	require.NoError(t, interp.ImportModule(module, ""))
+	memo := findMemo(t, interp, "RATE")
+
+	forks := make([]*Interpreter, 6)
+	for j := range forks {
+		forks[j] = interp.Fork()
+		require.NoError(t, forks[j].Run(fmt.Sprintf(`: CALLER-%d RATE ;`, j)))
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(forks))
+	for j, fork := range forks {
+		wg.Add(1)
+		go func(j int, fork *Interpreter) {
+			defer wg.Done()
+			errs[j] = fork.Run(fmt.Sprintf(`CALLER-%d`, j))
+		}(j, fork)
+	}
+	require.Eventually(t, func() bool { return memo.Status().Refreshing }, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	// Each caller's trace has only its own frames
+	for j, err := range errs {
+		require.ErrorContains(t, err, "no rates")
+		callers := []string{}
+		for _, frame := range GetStackTrace(err) {
+			if strings.HasPrefix(frame.Word, "CALLER-") {
+				callers = append(callers, frame.Word)
+			}
+		}
+		assert.Equal(t, []string{fmt.Sprintf("CALLER-%d", j)}, callers)
+	}
+}
+
+func TestMemo_WaiterStopsWhenCancelled(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	module, _ := newCountingMemoModule(release)
+	interp := NewInterpreter()
+	require.NoError(t, interp.ImportModule(module, ""))
+	memo := findMemo(t, interp, "RATE")
+
+	computing := interp.Fork()
+	go computing.Run(`RATE`)
+	require.Eventually(t, func() bool { return memo.Status().Refreshing }, time.Second, time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	waiter := interp.Fork()
+	done := make(chan error, 1)
+	go func() { done <- waiter.RunContext(ctx, `RATE`) }()
+
+	select {
+	case err := <-done:
+		assert.True(t, errors.Is(err, ErrDeadlineExceeded), "got %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("Waiter kept waiting after its context was done")
+	}
+}
+
+func TestMemo_CancelledComputationIsRecomputed(t *testing.T) {
+	release := make(chan struct{})
+	var runs int64
+	module := NewModule("rates", `@: RATE  COMPUTE ;`)
+	module.AddModuleWord("COMPUTE", func(interp *Interpreter) error {
+		n := atomic.AddInt64(&runs, 1)
+		select {
+		case <-release:
+		case <-interp.Context().Done():
+			return interp.CheckContext()
+		}
+		interp.StackPush(n)
+		return nil
+	})
+	module.AddExportable([]string{"RATE"})
+	interp := NewInterpreter()
+	require.NoError(t, interp.ImportModule(module, ""))
+	memo := findMemo(t, interp, "RATE")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	computing := interp.Fork()
+	computed := make(chan error, 1)
+	go func() { computed <- computing.RunContext(ctx, `RATE`) }()
+	require.Eventually(t, func() bool { return memo.Status().Refreshing }, time.Second, time.Millisecond)
+
+	// The waiter has an error handler, which a cancellation would bypass
+	waiter := interp.Fork()
+	require.NoError(t, waiter.Run(`: CALLER RATE ;`))
+	handled := false
+	waiter.GetAppModule().FindWord("CALLER").(*DefinitionWord).AddErrorHandler(func(err error, w Word, i *Interpreter) error {
+		handled = true
+		return nil
+	})
+	waited := make(chan error, 1)
+	go func() { waited <- waiter.Run(`CALLER`) }()
+	time.Sleep(10 * time.Millisecond)
+
+	cancel()
+	assert.True(t, errors.Is(<-computed, ErrCancelled))
+	require.Eventually(t, func() bool { return atomic.LoadInt64(&runs) == 2 }, time.Second, time.Millisecond)
+	close(release)
+
+	require.NoError(t, <-waited)
+	assert.False(t, handled)
+	assert.Equal(t, int64(2), waiter.StackPop())
+}
@@ -1,5 +1,11 @@
 package forthic
 
+import "sync/atomic"
+
+// dictionaryVersion is incremented whenever words or variables are added to
+// any module, so that caches of word lookups can tell when they are stale
+var dictionaryVersion uint64
+
 // Module - Container for words, variables, and imported modules
 //
 // Modules provide namespacing and code organization in Forthic.
@@ -12,16 +18,26 @@ package forthic
 // - Module duplication for isolated execution contexts
 type Module struct {
 	words          []Word
+	wordIndex      map[string]Word // name -> last added word with that name
 	exportable     []string
 	variables      map[string]*Variable
+	variableWords  map[string]Word   // name -> word that pushes the variable
+	definitions    []*DefinitionWord // compiled from source in this module, in order
+	inline         bool              // created by "{name" in Forthic code
+	shared         bool              // dictionary is shared with a fork; see unshare
 	modules        map[string]*Module
 	modulePrefixes map[string]map[string]bool // module_name -> set of prefixes
 	name           string
 	forthicCode    string
 	interp         *Interpreter
+	version        uint64 // incremented whenever words or variables are added
+	initialized    bool   // true once forthicCode has been run
 }
 
 // NewModule creates a new Module
+// forthicCode, if given, is run in the module's context the first time the
+// module is registered with an interpreter, so a Go module can define some
+// of its words in Forthic.
 func NewModule(name string, forthicCode ...string) *Module {
 	code := ""
 	if len(forthicCode) > 0 {
@@ -30,8 +46,10 @@ func NewModule(name string, forthicCode ...string) *Module {
 
 	return &Module{
 		words:          make([]Word, 0),
+		wordIndex:      make(map[string]Word),
 		exportable:     make([]string, 0),
 		variables:      make(map[string]*Variable),
+		variableWords:  make(map[string]Word),
 		modules:        make(map[string]*Module),
 		modulePrefixes: make(map[string]map[string]bool),
 		name:           name,
@@ -65,18 +83,23 @@ func (m *Module) GetInterp() (*Interpreter, error) {
 // Dup creates a duplicate of the module
 func (m *Module) Dup() *Module {
 	result := NewModule(m.name, m.forthicCode)
+	result.initialized = m.initialized
 
 	// Copy words slice
 	result.words = make([]Word, len(m.words))
 	copy(result.words, m.words)
+	for name, word := range m.wordIndex {
+		result.wordIndex[name] = word
+	}
+	result.definitions = append(result.definitions, m.definitions...)
 
 	// Copy exportable slice
 	result.exportable = make([]string, len(m.exportable))
 	copy(result.exportable, m.exportable)
 
 	// Copy variables
-	for key, variable := range m.variables {
-		result.variables[key] = variable.Dup()
+	for _, variable := range m.variables {
+		result.setVariable(variable.Dup())
 	}
 
 	// Copy module references (shallow copy)
@@ -113,6 +136,7 @@ func (m *Module) FindModule(name string) *Module {
 
 // RegisterModule registers a module with a prefix
 func (m *Module) RegisterModule(moduleName string, prefix string, module *Module) {
+	m.recordChange()
 	m.modules[moduleName] = module
 
 	if m.modulePrefixes[moduleName] == nil {
@@ -147,27 +171,44 @@ func (m *Module) ImportModule(prefix string, module *Module, interp *Interpreter
 
 // AddWord adds a word to the module
 func (m *Module) AddWord(word Word) {
-	m.words = append(m.words, word)
+	m.appendWord(word)
+	m.touch()
 }
 
 // AddMemoWords adds memo word and refresh variants
 func (m *Module) AddMemoWords(word Word) *ModuleMemoWord {
 	memoWord := NewModuleMemoWord(word)
-	m.words = append(m.words, memoWord)
-	m.words = append(m.words, NewModuleMemoBangWord(memoWord))
-	m.words = append(m.words, NewModuleMemoBangAtWord(memoWord))
+	m.appendWord(memoWord)
+	m.appendWord(NewModuleMemoBangWord(memoWord))
+	m.appendWord(NewModuleMemoBangAtWord(memoWord))
+	m.touch()
 	return memoWord
 }
 
+// appendWord adds a word to the dictionary and its index
+func (m *Module) appendWord(word Word) {
+	m.recordChange()
+	m.words = append(m.words, word)
+	m.wordIndex[word.GetName()] = word
+}
+
+// touch records that the module's words or variables have changed
+func (m *Module) touch() {
+	m.version++
+	atomic.AddUint64(&dictionaryVersion, 1)
+}
+
 // AddExportable adds word names to the exportable list
 func (m *Module) AddExportable(names []string) {
+	m.recordChange()
 	m.exportable = append(m.exportable, names...)
 }
 
 // AddExportableWord adds a word and marks it as exportable
 func (m *Module) AddExportableWord(word Word) {
-	m.words = append(m.words, word)
+	m.appendWord(word)
 	m.exportable = append(m.exportable, word.GetName())
+	m.touch()
 }
 
 // AddModuleWord creates a ModuleWord and marks it as exportable
@@ -176,6 +217,22 @@ func (m *Module) AddModuleWord(wordName string, handler func(*Interpreter) error
 	m.AddExportableWord(word)
 }
 
+// AddModuleWordWithOptions creates a ModuleWord that accepts the options in
+// schema, and marks it as exportable
+// The handler receives the options, validated and with defaults filled in.
+func (m *Module) AddModuleWordWithOptions(wordName string, schema OptionSchema, handler func(*Interpreter, *WordOptions) error) {
+	word := NewModuleWordWithOptions(wordName, schema, handler)
+	m.AddExportableWord(word)
+}
+
+// Definitions returns the words defined in this module from Forthic source
+// (with ":" or "@:"), in the order they were defined
+func (m *Module) Definitions() []*DefinitionWord {
+	result := make([]*DefinitionWord, len(m.definitions))
+	copy(result, m.definitions)
+	return result
+}
+
 // ExportableWords returns all exportable words
 func (m *Module) ExportableWords() []Word {
 	result := make([]Word, 0)
@@ -193,6 +250,27 @@ func (m *Module) ExportableWords() []Word {
 	return result
 }
 
+// WordNames returns the names of the module's words and variables
+// Each name appears once, even if the word was redefined.
+func (m *Module) WordNames() []string {
+	seen := make(map[string]bool)
+	result := make([]string, 0, len(m.words)+len(m.variables))
+	for _, word := range m.words {
+		name := word.GetName()
+		if !seen[name] {
+			seen[name] = true
+			result = append(result, name)
+		}
+	}
+	for name := range m.variables {
+		if !seen[name] {
+			seen[name] = true
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
 // FindWord finds a word by name (checks words then variables)
 func (m *Module) FindWord(name string) Word {
 	// Check dictionary words first
@@ -207,22 +285,18 @@ func (m *Module) FindWord(name string) Word {
 }
 
 // FindDictionaryWord finds a word in the word dictionary
-// Searches from end to beginning (last added word wins)
+// If several words share a name, the last added word wins.
 func (m *Module) FindDictionaryWord(wordName string) Word {
-	for i := len(m.words) - 1; i >= 0; i-- {
-		w := m.words[i]
-		if w.GetName() == wordName {
-			return w
-		}
+	if word, ok := m.wordIndex[wordName]; ok {
+		return word
 	}
 	return nil
 }
 
 // FindVariable finds a variable and returns it as a PushValueWord
 func (m *Module) FindVariable(varName string) Word {
-	variable, ok := m.variables[varName]
-	if ok {
-		return NewPushValueWord(varName, variable)
+	if word, ok := m.variableWords[varName]; ok {
+		return word
 	}
 	return nil
 }
@@ -234,21 +308,43 @@ func (m *Module) FindVariable(varName string) Word {
 // AddVariable adds a variable to the module
 func (m *Module) AddVariable(name string, value interface{}) {
 	if m.variables[name] == nil {
-		m.variables[name] = NewVariable(name, value)
+		m.setVariable(NewVariable(name, value))
+		m.touch()
 	}
 }
 
+// setVariable adds a variable along with the word that pushes it
+func (m *Module) setVariable(variable *Variable) {
+	m.recordChange()
+	name := variable.GetName()
+	m.variables[name] = variable
+	m.variableWords[name] = NewPushValueWord(name, variable)
+}
+
 // GetVariable returns a variable by name
 func (m *Module) GetVariable(name string) *Variable {
 	return m.variables[name]
 }
 
+// Variables returns a copy of the module's variables, keyed by name
+func (m *Module) Variables() map[string]*Variable {
+	result := make(map[string]*Variable, len(m.variables))
+	for name, variable := range m.variables {
+		result[name] = variable
+	}
+	return result
+}
+
 // ============================================================================
 // Additional Word Types for Module System
 // ============================================================================
 
 // ExecuteWord - Wrapper word that executes another word
 // Used for prefixed module imports (e.g., prefix.word)
+//
+// Handlers on the target word apply as they do when the target is executed
+// directly. If the target still fails, handlers added to the ExecuteWord
+// itself are tried.
 type ExecuteWord struct {
 	*BaseWord
 	targetWord Word
@@ -263,89 +359,21 @@ func NewExecuteWord(name string, targetWord Word) *ExecuteWord {
 }
 
 func (w *ExecuteWord) Execute(interp *Interpreter) error {
-	return w.targetWord.Execute(interp)
-}
-
-func (w *ExecuteWord) GetRuntimeInfo() *RuntimeInfo {
-	return w.targetWord.GetRuntimeInfo()
-}
-
-// ModuleMemoWord - Memoized word that caches its result
-type ModuleMemoWord struct {
-	*BaseWord
-	word     Word
-	hasValue bool
-	value    interface{}
-}
-
-// NewModuleMemoWord creates a new ModuleMemoWord
-func NewModuleMemoWord(word Word) *ModuleMemoWord {
-	return &ModuleMemoWord{
-		BaseWord: NewBaseWord(word.GetName()),
-		word:     word,
-		hasValue: false,
-		value:    nil,
-	}
-}
-
-func (w *ModuleMemoWord) Refresh(interp *Interpreter) error {
-	err := w.word.Execute(interp)
+	err := w.targetWord.Execute(interp)
 	if err != nil {
-		return err
-	}
-	w.value = interp.StackPop()
-	w.hasValue = true
-	return nil
-}
-
-func (w *ModuleMemoWord) Execute(interp *Interpreter) error {
-	if !w.hasValue {
-		err := w.Refresh(interp)
-		if err != nil {
-			return err
+		if handledErr := w.TryErrorHandlers(err, w, interp); handledErr == nil {
+			return nil
 		}
+		return err
 	}
-	interp.StackPush(w.value)
 	return nil
 }
 
-// ModuleMemoBangWord - Forces refresh of a memoized word
-type ModuleMemoBangWord struct {
-	*BaseWord
-	memoWord *ModuleMemoWord
-}
-
-// NewModuleMemoBangWord creates a new ModuleMemoBangWord
-func NewModuleMemoBangWord(memoWord *ModuleMemoWord) *ModuleMemoBangWord {
-	return &ModuleMemoBangWord{
-		BaseWord: NewBaseWord(memoWord.GetName() + "!"),
-		memoWord: memoWord,
-	}
-}
-
-func (w *ModuleMemoBangWord) Execute(interp *Interpreter) error {
-	return w.memoWord.Refresh(interp)
+// GetTargetWord returns the word this word executes
+func (w *ExecuteWord) GetTargetWord() Word {
+	return w.targetWord
 }
 
-// ModuleMemoBangAtWord - Refreshes a memoized word and returns its value
-type ModuleMemoBangAtWord struct {
-	*BaseWord
-	memoWord *ModuleMemoWord
-}
-
-// NewModuleMemoBangAtWord creates a new ModuleMemoBangAtWord
-func NewModuleMemoBangAtWord(memoWord *ModuleMemoWord) *ModuleMemoBangAtWord {
-	return &ModuleMemoBangAtWord{
-		BaseWord: NewBaseWord(memoWord.GetName() + "!@"),
-		memoWord: memoWord,
-	}
-}
-
-func (w *ModuleMemoBangAtWord) Execute(interp *Interpreter) error {
-	err := w.memoWord.Refresh(interp)
-	if err != nil {
-		return err
-	}
-	interp.StackPush(w.memoWord.value)
-	return nil
+func (w *ExecuteWord) GetRuntimeInfo() *RuntimeInfo {
+	return w.targetWord.GetRuntimeInfo()
 }
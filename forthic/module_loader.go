@@ -0,0 +1,216 @@
+package forthic
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ModuleFileExtension is the extension of Forthic module files
+const ModuleFileExtension = ".forthic"
+
+// ModuleSource - The Forthic source of a module, as located by a ModuleLoader
+type ModuleSource struct {
+	Name string // Module name
+	File string // Where the source came from, used in error locations
+	Code string // Forthic code defining the module's words
+}
+
+// ModuleLoader locates the source of modules defined in Forthic
+//
+// When USE-MODULES names a module that is neither registered nor available
+// from a module factory, the interpreter asks its ModuleLoader for the
+// module's source, runs the source in the context of a new module, and
+// registers the result under the requested name.
+//
+// LoadModuleSource returns an UnknownModuleError if the module can't be found.
+type ModuleLoader interface {
+	LoadModuleSource(name string) (*ModuleSource, error)
+}
+
+// ============================================================================
+// FSModuleLoader
+// ============================================================================
+
+// moduleRoot is one directory searched for module files
+type moduleRoot struct {
+	fsys    fs.FS
+	dir     string // Directory within fsys
+	display string // OS directory that fsys represents, if any
+}
+
+// file returns how a path within the root is reported in locations and errors
+func (r moduleRoot) file(name string) string {
+	if r.display == "" {
+		return name
+	}
+	return filepath.Join(r.display, filepath.FromSlash(name))
+}
+
+// FSModuleLoader - Loads modules from ".forthic" files
+//
+// The module "reports" is loaded from the first "reports.forthic" found in
+// the search paths; "lib/reports" is loaded from "lib/reports.forthic".
+// Sources are read once and cached.
+type FSModuleLoader struct {
+	roots []moduleRoot
+
+	mu    sync.Mutex
+	cache map[string]*ModuleSource
+}
+
+// NewFSModuleLoader creates a loader that searches directories within fsys
+// Search paths are slash-separated paths within fsys; with none, the root
+// of fsys is searched. Any fs.FS works, including embed.FS.
+func NewFSModuleLoader(fsys fs.FS, searchPaths ...string) *FSModuleLoader {
+	if len(searchPaths) == 0 {
+		searchPaths = []string{"."}
+	}
+	roots := make([]moduleRoot, 0, len(searchPaths))
+	for _, dir := range searchPaths {
+		roots = append(roots, moduleRoot{fsys: fsys, dir: dir})
+	}
+	return newFSModuleLoader(roots)
+}
+
+// NewDirModuleLoader creates a loader that searches directories on disk
+func NewDirModuleLoader(dirs ...string) *FSModuleLoader {
+	roots := make([]moduleRoot, 0, len(dirs))
+	for _, dir := range dirs {
+		roots = append(roots, moduleRoot{fsys: os.DirFS(dir), dir: ".", display: dir})
+	}
+	return newFSModuleLoader(roots)
+}
+
+func newFSModuleLoader(roots []moduleRoot) *FSModuleLoader {
+	return &FSModuleLoader{
+		roots: roots,
+		cache: make(map[string]*ModuleSource),
+	}
+}
+
+// LoadModuleSource finds and reads the file for the named module
+func (l *FSModuleLoader) LoadModuleSource(name string) (*ModuleSource, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if source, ok := l.cache[name]; ok {
+		return source, nil
+	}
+
+	if !fs.ValidPath(name) || name == "." {
+		return nil, NewModuleError(name, "Invalid module name")
+	}
+
+	searched := make([]string, 0, len(l.roots))
+	for _, root := range l.roots {
+		filePath := path.Join(root.dir, name+ModuleFileExtension)
+		code, err := fs.ReadFile(root.fsys, filePath)
+		if errors.Is(err, fs.ErrNotExist) {
+			searched = append(searched, root.file(filePath))
+			continue
+		}
+		if err != nil {
+			return nil, NewModuleError(name, fmt.Sprintf("Cannot read %s", root.file(filePath))).WithCause(err)
+		}
+
+		source := &ModuleSource{Name: name, File: root.file(filePath), Code: string(code)}
+		l.cache[name] = source
+		return source, nil
+	}
+
+	err := NewUnknownModuleError(name)
+	if len(searched) > 0 {
+		err.Message += fmt.Sprintf(" (searched %s)", strings.Join(searched, ", "))
+	}
+	return nil, err
+}
+
+// ============================================================================
+// Interpreter Module Loading
+// ============================================================================
+
+// SetModuleLoader sets the loader used to find modules defined in Forthic
+func (i *Interpreter) SetModuleLoader(loader ModuleLoader) {
+	i.moduleLoader = loader
+}
+
+// GetModuleLoader returns the interpreter's module loader, if any
+func (i *Interpreter) GetModuleLoader() ModuleLoader {
+	return i.moduleLoader
+}
+
+// loadModule creates a module from the source provided by the module loader
+func (i *Interpreter) loadModule(name string) (*Module, error) {
+	for j, loading := range i.loadingModules {
+		if loading == name {
+			chain := append(append([]string{}, i.loadingModules[j:]...), name)
+			return nil, NewModuleError(name, "Circular module dependency: "+strings.Join(chain, " -> "))
+		}
+	}
+
+	source, err := i.moduleLoader.LoadModuleSource(name)
+	if err != nil {
+		return nil, err
+	}
+
+	i.loadingModules = append(i.loadingModules, name)
+	defer func() {
+		i.loadingModules = i.loadingModules[:len(i.loadingModules)-1]
+	}()
+
+	module := NewModule(name, source.Code)
+	module.SetInterp(i)
+	if err := i.initModule(module, source.File); err != nil {
+		return nil, err
+	}
+	return module, nil
+}
+
+// initModule runs a module's Forthic code, once per module
+//
+// The code runs with the module on top of the module stack, so its
+// definitions become the module's words, and locations in errors name file.
+// Definitions are exported if named with EXPORT. A module that ends up with
+// no exports at all (as with a module file that doesn't use EXPORT) exports
+// every word.
+func (i *Interpreter) initModule(module *Module, file string) error {
+	if module.initialized {
+		return nil
+	}
+	module.initialized = true
+	if module.forthicCode == "" {
+		return nil
+	}
+
+	if err := i.runModuleCode(module, file); err != nil {
+		module.initialized = false
+		return NewModuleError(module.name, fmt.Sprintf("Failed to load %s", file)).WithCause(err)
+	}
+
+	if len(module.exportable) == 0 {
+		names := make([]string, 0, len(module.words))
+		for _, word := range module.words {
+			names = append(names, word.GetName())
+		}
+		module.AddExportable(names)
+	}
+	return nil
+}
+
+// runModuleCode runs a module's Forthic code with the module as the current module
+func (i *Interpreter) runModuleCode(module *Module, file string) error {
+	depth := len(i.moduleStack)
+	i.ModuleStackPush(module)
+	defer func() {
+		for len(i.moduleStack) > depth {
+			i.ModuleStackPop()
+		}
+	}()
+	return i.RunSource(file, module.forthicCode)
+}
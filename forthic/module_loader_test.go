@@ -0,0 +1,150 @@
+package forthic
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newUseModulesInterpreter returns an interpreter with a USE-MODULES word
+// and the given module loader
+func newUseModulesInterpreter(loader ModuleLoader) *Interpreter {
+	module := NewModule("use", "")
+	module.AddModuleWord("USE-MODULES", func(interp *Interpreter) error {
+		return interp.UseModules(interp.StackPop().([]interface{}))
+	})
+	interp := NewInterpreter(module)
+	interp.SetModuleLoader(loader)
+	return interp
+}
+
+func TestModuleLoader_LoadsWithPrefix(t *testing.T) {
+	fsys := fstest.MapFS{
+		"lib/reports.forthic": {Data: []byte(": TITLE 'Report' ;\n: COUNT 3 ;")},
+	}
+	interp := newUseModulesInterpreter(NewFSModuleLoader(fsys, "lib"))
+
+	err := interp.Run(`[["reports" "r"]] USE-MODULES r.TITLE r.COUNT`)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), interp.StackPop())
+	assert.Equal(t, "Report", interp.StackPop())
+
+	module, err := interp.FindModule("reports")
+	assert.NoError(t, err)
+	assert.Equal(t, "reports", module.GetName())
+}
+
+func TestModuleLoader_HonorsExport(t *testing.T) {
+	fsys := fstest.MapFS{
+		"reports.forthic": {Data: []byte(`: HELPER 2 ; : DOUBLE HELPER * ; ["DOUBLE"] EXPORT`)},
+	}
+	module := NewModule("core-ish", "")
+	module.AddModuleWord("*", func(interp *Interpreter) error {
+		b := interp.StackPop().(int64)
+		a := interp.StackPop().(int64)
+		interp.StackPush(a * b)
+		return nil
+	})
+	module.AddModuleWord("EXPORT", func(interp *Interpreter) error {
+		names := []string{}
+		for _, name := range interp.StackPop().([]interface{}) {
+			names = append(names, name.(string))
+		}
+		interp.CurModule().AddExportable(names)
+		return nil
+	})
+	interp := newUseModulesInterpreter(NewFSModuleLoader(fsys))
+	interp.ImportModule(module, "")
+
+	err := interp.Run(`["reports"] USE-MODULES 5 DOUBLE`)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), interp.StackPop())
+
+	err = interp.Run(`HELPER`)
+	assert.True(t, errors.Is(err, ErrUnknownWord))
+}
+
+func TestModuleLoader_SearchPathOrderAndCache(t *testing.T) {
+	fsys := fstest.MapFS{
+		"first/util.forthic":  {Data: []byte(": WHICH 'first' ;")},
+		"second/util.forthic": {Data: []byte(": WHICH 'second' ;")},
+	}
+	loader := NewFSModuleLoader(fsys, "first", "second")
+
+	source, err := loader.LoadModuleSource("util")
+	assert.NoError(t, err)
+	assert.Equal(t, "first/util.forthic", source.File)
+
+	cached, err := loader.LoadModuleSource("util")
+	assert.NoError(t, err)
+	assert.Same(t, source, cached)
+}
+
+func TestModuleLoader_MissingModule(t *testing.T) {
+	interp := newUseModulesInterpreter(NewFSModuleLoader(fstest.MapFS{}, "lib", "vendor"))
+
+	err := interp.Run(`["reports"] USE-MODULES`)
+	var unknown *UnknownModuleError
+	assert.True(t, errors.As(err, &unknown))
+	assert.Equal(t, "reports", unknown.Module)
+	assert.Contains(t, err.Error(), "lib/reports.forthic, vendor/reports.forthic")
+}
+
+func TestModuleLoader_InvalidName(t *testing.T) {
+	loader := NewFSModuleLoader(fstest.MapFS{})
+	_, err := loader.LoadModuleSource("../secrets")
+	assert.True(t, errors.Is(err, ErrModule))
+}
+
+func TestModuleLoader_CycleDetection(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.forthic": {Data: []byte(`["b"] USE-MODULES : A 1 ;`)},
+		"b.forthic": {Data: []byte(`["a"] USE-MODULES : B 2 ;`)},
+	}
+	interp := newUseModulesInterpreter(NewFSModuleLoader(fsys))
+
+	err := interp.Run(`["a"] USE-MODULES`)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Circular module dependency: a -> b -> a")
+
+	// The interpreter is left usable
+	assert.Equal(t, 1, len(interp.ModuleStack()))
+	assert.NoError(t, interp.Run(`1`))
+}
+
+func TestModuleLoader_ErrorNamesFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"broken.forthic": {Data: []byte(": OK 1 ;\nNOPE")},
+	}
+	interp := newUseModulesInterpreter(NewFSModuleLoader(fsys))
+
+	err := interp.Run(`["broken"] USE-MODULES`)
+	assert.True(t, errors.Is(err, ErrModule))
+
+	var unknown *UnknownWordError
+	assert.True(t, errors.As(err, &unknown))
+	assert.Equal(t, "broken.forthic", unknown.Location.File)
+	assert.Equal(t, 2, unknown.Location.Line)
+
+	// A module that failed to load is not registered
+	_, err = interp.FindModule("broken")
+	assert.Error(t, err)
+}
+
+func TestModuleLoader_Dir(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "greet.forthic"), []byte(": HELLO 'hello' ;"), 0644))
+
+	loader := NewDirModuleLoader(dir)
+	source, err := loader.LoadModuleSource("greet")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "greet.forthic"), source.File)
+
+	interp := newUseModulesInterpreter(loader)
+	assert.NoError(t, interp.Run(`["greet"] USE-MODULES HELLO`))
+	assert.Equal(t, "hello", interp.StackPop())
+}
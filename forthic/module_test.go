@@ -0,0 +1,158 @@
+package forthic
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newModuleWithCode returns a Go module with companion words written in
+// Forthic, and a counter of how many times its code has run
+func newModuleWithCode() (*Module, *int) {
+	runs := 0
+	module := NewModule("greeter", `
+		COUNT-RUN
+		: PUNCTUATE  "!" CONCAT2 ;
+		: GREET      "Hello, " SWAP CONCAT2 PUNCTUATE ;
+		["GREET"] EXPORT
+	`)
+	module.AddModuleWord("COUNT-RUN", func(interp *Interpreter) error {
+		runs++
+		return nil
+	})
+	module.AddModuleWord("CONCAT2", func(interp *Interpreter) error {
+		b := interp.StackPop().(string)
+		a := interp.StackPop().(string)
+		interp.StackPush(a + b)
+		return nil
+	})
+	module.AddModuleWord("SWAP", func(interp *Interpreter) error {
+		b := interp.StackPop()
+		a := interp.StackPop()
+		interp.StackPush(b)
+		interp.StackPush(a)
+		return nil
+	})
+	module.AddModuleWord("EXPORT", func(interp *Interpreter) error {
+		names := []string{}
+		for _, name := range interp.StackPop().([]interface{}) {
+			names = append(names, name.(string))
+		}
+		interp.CurModule().AddExportable(names)
+		return nil
+	})
+	return module, &runs
+}
+
+func TestModule_ForthicCodeRunsOnImport(t *testing.T) {
+	module, runs := newModuleWithCode()
+	interp := NewInterpreter()
+	assert.NoError(t, interp.ImportModule(module, ""))
+	assert.Equal(t, 1, *runs)
+
+	assert.NoError(t, interp.Run(`"World" GREET`))
+	assert.Equal(t, "Hello, World!", interp.StackPop())
+
+	// PUNCTUATE was not exported
+	err := interp.Run(`"x" PUNCTUATE`)
+	assert.True(t, errors.Is(err, ErrUnknownWord))
+}
+
+func TestModule_ForthicCodeRunsOnce(t *testing.T) {
+	module, runs := newModuleWithCode()
+	interp := NewInterpreter()
+	assert.NoError(t, interp.ImportModule(module, ""))
+	assert.NoError(t, interp.ImportModule(module, "g"))
+	assert.NoError(t, interp.RegisterModule(module))
+	assert.Equal(t, 1, *runs)
+
+	assert.NoError(t, interp.Run(`"Go" g.GREET`))
+	assert.Equal(t, "Hello, Go!", interp.StackPop())
+}
+
+func TestModule_ForthicCodeRunsForFactoryModules(t *testing.T) {
+	interp := NewInterpreter()
+	var runs *int
+	interp.RegisterModuleFactory("greeter", func() *Module {
+		var module *Module
+		module, runs = newModuleWithCode()
+		return module
+	})
+
+	assert.NoError(t, interp.UseModules([]interface{}{[]interface{}{"greeter", "g"}}))
+	assert.Equal(t, 1, *runs)
+	assert.NoError(t, interp.Run(`"Factory" g.GREET`))
+	assert.Equal(t, "Hello, Factory!", interp.StackPop())
+}
+
+func TestModule_ForthicCodeErrorNamesModule(t *testing.T) {
+	module := NewModule("broken", ": OK 1 ;\nNOPE")
+	interp := NewInterpreter()
+
+	err := interp.ImportModule(module, "")
+	var moduleErr *ModuleError
+	assert.True(t, errors.As(err, &moduleErr))
+	assert.Equal(t, "broken", moduleErr.Module)
+
+	var unknown *UnknownWordError
+	assert.True(t, errors.As(err, &unknown))
+	assert.Equal(t, "broken", unknown.Location.File)
+	assert.Equal(t, 2, unknown.Location.Line)
+
+	// The module was neither registered nor imported
+	_, err = interp.FindModule("broken")
+	assert.True(t, errors.Is(err, ErrUnknownModule))
+	assert.Equal(t, 1, len(interp.ModuleStack()))
+}
+
+func TestModule_NewInterpreterPanicsOnModuleCodeError(t *testing.T) {
+	assert.Panics(t, func() {
+		NewInterpreter(NewModule("broken", "NOPE"))
+	})
+}
+
+func TestModule_NewInterpreterWithModulesReturnsModuleCodeError(t *testing.T) {
+	interp, err := NewInterpreterWithModules(NewModule("broken", "NOPE"))
+	assert.Nil(t, interp)
+	var moduleErr *ModuleError
+	assert.True(t, errors.As(err, &moduleErr))
+	assert.Equal(t, "broken", moduleErr.Module)
+
+	interp, err = NewInterpreterWithModules(NewModule("fine", ": OK 1 ;"))
+	assert.NoError(t, err)
+	assert.NoError(t, interp.Run("OK"))
+	assert.Equal(t, int64(1), interp.StackPop())
+}
+
+func TestModule_FindDictionaryWordLastAddedWins(t *testing.T) {
+	module := NewModule("test", "")
+	first := NewPushValueWord("WORD", 1)
+	second := NewPushValueWord("WORD", 2)
+	module.AddWord(first)
+	module.AddWord(second)
+	assert.Same(t, second, module.FindDictionaryWord("WORD"))
+	assert.Nil(t, module.FindDictionaryWord("MISSING"))
+
+	// Duplicates have their own index
+	dup := module.Dup()
+	third := NewPushValueWord("WORD", 3)
+	dup.AddWord(third)
+	assert.Same(t, third, dup.FindDictionaryWord("WORD"))
+	assert.Same(t, second, module.FindDictionaryWord("WORD"))
+}
+
+func TestModule_FindVariableReusesWord(t *testing.T) {
+	module := NewModule("test", "")
+	module.AddVariable("x", 1)
+
+	word := module.FindVariable("x")
+	assert.NotNil(t, word)
+	assert.Same(t, word, module.FindVariable("x"))
+	assert.Nil(t, module.FindVariable("y"))
+
+	// A duplicate's variable word pushes the duplicate's variable
+	dup := module.Dup()
+	dupWord := dup.FindVariable("x").(*PushValueWord)
+	assert.Same(t, dup.GetVariable("x"), dupWord.value)
+}
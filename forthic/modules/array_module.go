@@ -31,7 +31,7 @@ func (m *ArrayModule) registerWords() {
 	m.AddModuleWord("NTH", m.nth)
 	m.AddModuleWord("LAST", m.last)
 	m.AddModuleWord("SLICE", m.slice)
-	m.AddModuleWord("TAKE", m.take)
+	m.AddModuleWordWithOptions("TAKE", takeOptions, m.take)
 	m.AddModuleWord("DROP", m.drop)
 	m.AddModuleWord("KEY-OF", m.keyOf)
 
@@ -41,28 +41,28 @@ func (m *ArrayModule) registerWords() {
 	m.AddModuleWord("UNION", m.union)
 
 	// Sort and shuffle
-	m.AddModuleWord("SORT", m.sortArray)
+	m.AddModuleWordWithOptions("SORT", sortOptions, m.sortArray)
 	m.AddModuleWord("SHUFFLE", m.shuffle)
 	m.AddModuleWord("ROTATE", m.rotate)
 
 	// Combine
 	m.AddModuleWord("ZIP", m.zip)
 	m.AddModuleWord("ZIP-WITH", m.zipWith)
-	m.AddModuleWord("FLATTEN", m.flatten)
+	m.AddModuleWordWithOptions("FLATTEN", flattenOptions, m.flatten)
 	m.AddModuleWord("UNPACK", m.unpack)
 
 	// Group and index
 	m.AddModuleWord("INDEX", m.index)
 	m.AddModuleWord("BY-FIELD", m.byField)
 	m.AddModuleWord("GROUP-BY-FIELD", m.groupByField)
-	m.AddModuleWord("GROUP-BY", m.groupBy)
+	m.AddModuleWordWithOptions("GROUP-BY", withKeyOptions, m.groupBy)
 	m.AddModuleWord("GROUPS-OF", m.groupsOf)
 
 	// Transform
-	m.AddModuleWord("MAP", m.mapArray)
-	m.AddModuleWord("SELECT", m.selectArray)
-	m.AddModuleWord("REDUCE", m.reduce)
-	m.AddModuleWord("FOREACH", m.foreach)
+	m.AddModuleWordWithOptions("MAP", mapOptions, m.mapArray)
+	m.AddModuleWordWithOptions("SELECT", selectOptions, m.selectArray)
+	m.AddModuleWordWithOptions("REDUCE", withKeyOptions, m.reduce)
+	m.AddModuleWordWithOptions("FOREACH", foreachOptions, m.foreach)
 	m.AddModuleWord("<REPEAT", m.repeat)
 }
 
@@ -79,6 +79,9 @@ func (m *ArrayModule) append_word(interp *forthic.Interpreter) error {
 	}
 
 	if arr, ok := container.([]interface{}); ok {
+		if err := interp.CheckCollectionSize(len(arr) + 1); err != nil {
+			return err
+		}
 		result := append(arr, item)
 		interp.StackPush(result)
 	} else {
@@ -266,34 +269,22 @@ func (m *ArrayModule) slice(interp *forthic.Interpreter) error {
 	return nil
 }
 
-func (m *ArrayModule) take(interp *forthic.Interpreter) error {
+// TAKE ( array n [options] -- taken [rest] )
+// With .push_rest, the elements not taken are pushed too.
+func (m *ArrayModule) take(interp *forthic.Interpreter, opts *forthic.WordOptions) error {
 	n := interp.StackPop()
 	arr := interp.StackPop()
 
-	if arr == nil {
-		interp.StackPush([]interface{}{})
-		return nil
-	}
-
 	slice, ok := arr.([]interface{})
 	if !ok {
-		interp.StackPush([]interface{}{})
-		return nil
+		slice = []interface{}{}
 	}
 
-	count := toInt(n)
-	if count <= 0 {
-		interp.StackPush([]interface{}{})
-		return nil
+	count := min(max(toInt(n), 0), len(slice))
+	interp.StackPush(slice[:count])
+	if isTruthy(opts.Get("push_rest", false)) {
+		interp.StackPush(slice[count:])
 	}
-
-	if count >= len(slice) {
-		interp.StackPush(slice)
-		return nil
-	}
-
-	result := slice[:count]
-	interp.StackPush(result)
 	return nil
 }
 
@@ -424,6 +415,10 @@ func (m *ArrayModule) union(interp *forthic.Interpreter) error {
 		}
 	}
 
+	if err := interp.CheckCollectionSize(len(result)); err != nil {
+		return err
+	}
+
 	interp.StackPush(result)
 	return nil
 }
@@ -432,7 +427,11 @@ func (m *ArrayModule) union(interp *forthic.Interpreter) error {
 // Sort
 // ========================================
 
-func (m *ArrayModule) sortArray(interp *forthic.Interpreter) error {
+// SORT ( array [options] -- sorted )
+// With .comparator, elements are sorted by a key: the value of a record
+// field, if the elements are records with that field, or else the value
+// the comparator leaves when run as Forthic code on each element.
+func (m *ArrayModule) sortArray(interp *forthic.Interpreter, opts *forthic.WordOptions) error {
 	arr := interp.StackPop()
 
 	slice, ok := arr.([]interface{})
@@ -445,15 +444,73 @@ func (m *ArrayModule) sortArray(interp *forthic.Interpreter) error {
 	result := make([]interface{}, len(slice))
 	copy(result, slice)
 
-	// Simple numeric/string sort
-	sort.Slice(result, func(i, j int) bool {
-		return compareValues(result[i], result[j]) < 0
+	if !opts.Has("comparator") {
+		// Simple numeric/string sort
+		sort.SliceStable(result, func(i, j int) bool {
+			return compareValues(result[i], result[j]) < 0
+		})
+		interp.StackPush(result)
+		return nil
+	}
+
+	comparator, ok := opts.Get("comparator").(string)
+	if !ok {
+		return forthicError("SORT comparator must be Forthic code or a field name")
+	}
+	keys, err := sortKeys(interp, result, comparator)
+	if err != nil {
+		return err
+	}
+
+	order := indexKeys(len(result))
+	sort.SliceStable(order, func(i, j int) bool {
+		return compareValues(keys[order[i].(int)], keys[order[j].(int)]) < 0
 	})
+	sorted := make([]interface{}, len(result))
+	for i, index := range order {
+		sorted[i] = result[index.(int)]
+	}
 
-	interp.StackPush(result)
+	interp.StackPush(sorted)
 	return nil
 }
 
+// sortKeys returns the key SORT orders each element by, for a .comparator
+func sortKeys(interp *forthic.Interpreter, items []interface{}, comparator string) ([]interface{}, error) {
+	keys := make([]interface{}, len(items))
+	if isFieldOf(comparator, items) {
+		for i, item := range items {
+			keys[i] = item.(map[string]interface{})[comparator]
+		}
+		return keys, nil
+	}
+
+	code := newCompiledCode(comparator)
+	for i, item := range items {
+		interp.StackPush(item)
+		if err := code.run(interp, i); err != nil {
+			return nil, err
+		}
+		keys[i] = interp.StackPop()
+	}
+	return keys, nil
+}
+
+// isFieldOf reports whether all items are records, and any has the field
+func isFieldOf(field string, items []interface{}) bool {
+	found := false
+	for _, item := range items {
+		rec, ok := item.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if _, ok := rec[field]; ok {
+			found = true
+		}
+	}
+	return found
+}
+
 // ========================================
 // Combine
 // ========================================
@@ -484,7 +541,9 @@ func (m *ArrayModule) zip(interp *forthic.Interpreter) error {
 	return nil
 }
 
-func (m *ArrayModule) flatten(interp *forthic.Interpreter) error {
+// FLATTEN ( array [options] -- flattened )
+// With .depth, only that many levels of nesting are flattened.
+func (m *ArrayModule) flatten(interp *forthic.Interpreter, opts *forthic.WordOptions) error {
 	arr := interp.StackPop()
 
 	slice, ok := arr.([]interface{})
@@ -494,8 +553,14 @@ func (m *ArrayModule) flatten(interp *forthic.Interpreter) error {
 	}
 
 	// Fully flatten by default (depth = -1 means infinite depth)
-	// TODO: Support depth option via ~> operator when implemented
-	result := flattenArray(slice, -1)
+	depth := -1
+	if opts.Has("depth") {
+		depth = max(toInt(opts.Get("depth")), 0)
+	}
+	result := flattenArray(slice, depth)
+	if err := interp.CheckCollectionSize(len(result)); err != nil {
+		return err
+	}
 	interp.StackPush(result)
 	return nil
 }
@@ -504,92 +569,204 @@ func (m *ArrayModule) flatten(interp *forthic.Interpreter) error {
 // Transform
 // ========================================
 
-func (m *ArrayModule) mapArray(interp *forthic.Interpreter) error {
+// MAP ( container code [options] -- result [errors] )
+// Maps the elements of an array or the values of a record. With .depth,
+// the elements of arrays and records nested that many levels down are
+// mapped instead. With .push_error, a failing element maps to NULL and an
+// array of each element's error (or NULL) is pushed after the result.
+func (m *ArrayModule) mapArray(interp *forthic.Interpreter, opts *forthic.WordOptions) error {
+	iter := iterOptionsOf(opts)
 	forthicCode := interp.StackPop()
-	arr := interp.StackPop()
+	container := interp.StackPop()
 
 	codeStr, ok := forthicCode.(string)
-	if !ok {
+	if !ok || !isCollection(container) {
 		interp.StackPush([]interface{}{})
 		return nil
 	}
+	code := newCompiledCode(codeStr)
 
-	slice, ok := arr.([]interface{})
-	if !ok {
-		interp.StackPush([]interface{}{})
-		return nil
+	result, leaves := mapLeaves(container, toInt(opts.Get("depth", 0)), nil)
+	keys := make([]interface{}, len(leaves))
+	values := make([]interface{}, len(leaves))
+	for i, leaf := range leaves {
+		keys[i] = leaf.key
+		values[i] = leaf.value
 	}
 
-	result := make([]interface{}, len(slice))
-	for i, item := range slice {
-		interp.StackPush(item)
-		err := interp.Run(codeStr)
+	errs := make([]error, len(leaves))
+	if iter.interps > 1 {
+		outputs, runErrs, err := code.runParallel(interp, values, keys, iter)
 		if err != nil {
 			return err
 		}
-		result[i] = interp.StackPop()
+		results, err := code.resultsOf(interp, outputs, runErrs, keys)
+		if err != nil {
+			return err
+		}
+		for i, leaf := range leaves {
+			leaf.set(results[i])
+		}
+		errs = runErrs
+	} else {
+		for i, leaf := range leaves {
+			caught, err := code.runItem(interp, leaf.key, leaf.value, iter)
+			if err != nil {
+				return err
+			}
+			if caught != nil {
+				errs[i] = caught
+				leaf.set(nil)
+				continue
+			}
+			leaf.set(interp.StackPop())
+		}
 	}
 
 	interp.StackPush(result)
+	if iter.pushError {
+		interp.StackPush(errorValues(errs))
+	}
 	return nil
 }
 
-func (m *ArrayModule) selectArray(interp *forthic.Interpreter) error {
+// mapLeaf is an element MAP runs its code on, and where its result goes
+type mapLeaf struct {
+	key   interface{}
+	value interface{}
+	set   func(result interface{})
+}
+
+// mapLeaves copies a container down to depth levels of nesting, appending
+// the elements at that depth to leaves. MAP fills in the copy with results.
+func mapLeaves(container interface{}, depth int, leaves []mapLeaf) (interface{}, []mapLeaf) {
+	keys, values, _ := entriesOf(container)
+
+	var result interface{}
+	var set func(i int, value interface{})
+	if _, ok := container.([]interface{}); ok {
+		arr := make([]interface{}, len(values))
+		result = arr
+		set = func(i int, value interface{}) { arr[i] = value }
+	} else {
+		rec := make(map[string]interface{}, len(values))
+		result = rec
+		set = func(i int, value interface{}) { rec[keys[i].(string)] = value }
+	}
+
+	for i, value := range values {
+		if depth > 0 && isCollection(value) {
+			var child interface{}
+			child, leaves = mapLeaves(value, depth-1, leaves)
+			set(i, child)
+			continue
+		}
+		i := i
+		leaves = append(leaves, mapLeaf{
+			key:   keys[i],
+			value: value,
+			set:   func(result interface{}) { set(i, result) },
+		})
+	}
+	return result, leaves
+}
+
+// isCollection reports whether a value is an array or a record
+func isCollection(value interface{}) bool {
+	switch value.(type) {
+	case []interface{}, map[string]interface{}:
+		return true
+	}
+	return false
+}
+
+// SELECT ( container code [options] -- selected )
+// Keeps the elements of an array, or the fields of a record, for which the
+// code leaves a truthy value.
+func (m *ArrayModule) selectArray(interp *forthic.Interpreter, opts *forthic.WordOptions) error {
+	iter := iterOptionsOf(opts)
 	forthicCode := interp.StackPop()
-	arr := interp.StackPop()
+	container := interp.StackPop()
 
 	codeStr, ok := forthicCode.(string)
 	if !ok {
 		interp.StackPush([]interface{}{})
 		return nil
 	}
+	code := newCompiledCode(codeStr)
 
-	slice, ok := arr.([]interface{})
+	keys, values, ok := entriesOf(container)
 	if !ok {
 		interp.StackPush([]interface{}{})
 		return nil
 	}
 
-	result := []interface{}{}
-	for _, item := range slice {
-		interp.StackPush(item)
-		err := interp.Run(codeStr)
+	keeps := make([]interface{}, len(values))
+	if iter.interps > 1 {
+		outputs, errs, err := code.runParallel(interp, values, keys, iter)
 		if err != nil {
 			return err
 		}
-		keep := interp.StackPop()
-		if isTruthy(keep) {
-			result = append(result, item)
+		keeps, err = code.resultsOf(interp, outputs, errs, keys)
+		if err != nil {
+			return err
+		}
+	} else {
+		for i, value := range values {
+			if _, err := code.runItem(interp, keys[i], value, iter); err != nil {
+				return err
+			}
+			keeps[i] = interp.StackPop()
+		}
+	}
+
+	if _, ok := container.(map[string]interface{}); ok {
+		result := make(map[string]interface{})
+		for i, keep := range keeps {
+			if isTruthy(keep) {
+				result[keys[i].(string)] = values[i]
+			}
 		}
+		interp.StackPush(result)
+		return nil
 	}
 
+	result := []interface{}{}
+	for i, keep := range keeps {
+		if isTruthy(keep) {
+			result = append(result, values[i])
+		}
+	}
 	interp.StackPush(result)
 	return nil
 }
 
-func (m *ArrayModule) reduce(interp *forthic.Interpreter) error {
+// REDUCE ( container initial code [options] -- result )
+// The code is run with the accumulator and each element on the stack (and
+// the element's key between them, with .with_key).
+func (m *ArrayModule) reduce(interp *forthic.Interpreter, opts *forthic.WordOptions) error {
+	iter := iterOptions{withKey: iterOptionsOf(opts).withKey}
 	forthicCode := interp.StackPop()
 	initial := interp.StackPop()
-	arr := interp.StackPop()
+	container := interp.StackPop()
 
 	codeStr, ok := forthicCode.(string)
 	if !ok {
 		interp.StackPush(initial)
 		return nil
 	}
+	code := newCompiledCode(codeStr)
 
-	slice, ok := arr.([]interface{})
+	keys, values, ok := entriesOf(container)
 	if !ok {
 		interp.StackPush(initial)
 		return nil
 	}
 
 	accumulator := initial
-	for _, item := range slice {
+	for i, value := range values {
 		interp.StackPush(accumulator)
-		interp.StackPush(item)
-		err := interp.Run(codeStr)
-		if err != nil {
+		if _, err := code.runItem(interp, keys[i], value, iter); err != nil {
 			return err
 		}
 		accumulator = interp.StackPop()
@@ -695,6 +872,7 @@ func (m *ArrayModule) zipWith(interp *forthic.Interpreter) error {
 		interp.StackPush([]interface{}{})
 		return nil
 	}
+	code := newCompiledCode(codeStr)
 
 	slice1, ok1 := arr1.([]interface{})
 	slice2, ok2 := arr2.([]interface{})
@@ -712,7 +890,7 @@ func (m *ArrayModule) zipWith(interp *forthic.Interpreter) error {
 		}
 		interp.StackPush(slice1[i])
 		interp.StackPush(value2)
-		err := interp.Run(codeStr)
+		err := code.run(interp, i)
 		if err != nil {
 			return err
 		}
@@ -762,6 +940,7 @@ func (m *ArrayModule) index(interp *forthic.Interpreter) error {
 		interp.StackPush(map[string]interface{}{})
 		return nil
 	}
+	code := newCompiledCode(codeStr)
 
 	slice, ok := items.([]interface{})
 	if !ok {
@@ -770,9 +949,9 @@ func (m *ArrayModule) index(interp *forthic.Interpreter) error {
 	}
 
 	result := make(map[string]interface{})
-	for _, item := range slice {
+	for i, item := range slice {
 		interp.StackPush(item)
-		err := interp.Run(codeStr)
+		err := code.run(interp, i)
 		if err != nil {
 			return err
 		}
@@ -897,7 +1076,11 @@ func (m *ArrayModule) groupByField(interp *forthic.Interpreter) error {
 	return nil
 }
 
-func (m *ArrayModule) groupBy(interp *forthic.Interpreter) error {
+// GROUP-BY ( container code [options] -- groups )
+// Groups elements by the value the code leaves for each (run with the
+// element's key too, with .with_key).
+func (m *ArrayModule) groupBy(interp *forthic.Interpreter, opts *forthic.WordOptions) error {
+	iter := iterOptions{withKey: iterOptionsOf(opts).withKey}
 	forthicCode := interp.StackPop()
 	items := interp.StackPop()
 
@@ -906,41 +1089,19 @@ func (m *ArrayModule) groupBy(interp *forthic.Interpreter) error {
 		interp.StackPush(map[string]interface{}{})
 		return nil
 	}
-
-	if items == nil {
-		interp.StackPush(map[string]interface{}{})
-		return nil
-	}
+	code := newCompiledCode(codeStr)
 
 	result := make(map[string]interface{})
-
-	if arr, ok := items.([]interface{}); ok {
-		for _, item := range arr {
-			interp.StackPush(item)
-			err := interp.Run(codeStr)
-			if err != nil {
-				return err
-			}
-			groupKey := toString(interp.StackPop())
-			if existing, ok := result[groupKey].([]interface{}); ok {
-				result[groupKey] = append(existing, item)
-			} else {
-				result[groupKey] = []interface{}{item}
-			}
+	keys, values, _ := entriesOf(items)
+	for i, item := range values {
+		if _, err := code.runItem(interp, keys[i], item, iter); err != nil {
+			return err
 		}
-	} else if rec, ok := items.(map[string]interface{}); ok {
-		for _, item := range rec {
-			interp.StackPush(item)
-			err := interp.Run(codeStr)
-			if err != nil {
-				return err
-			}
-			groupKey := toString(interp.StackPop())
-			if existing, ok := result[groupKey].([]interface{}); ok {
-				result[groupKey] = append(existing, item)
-			} else {
-				result[groupKey] = []interface{}{item}
-			}
+		groupKey := toString(interp.StackPop())
+		if existing, ok := result[groupKey].([]interface{}); ok {
+			result[groupKey] = append(existing, item)
+		} else {
+			result[groupKey] = []interface{}{item}
 		}
 	}
 
@@ -985,7 +1146,12 @@ func (m *ArrayModule) groupsOf(interp *forthic.Interpreter) error {
 // Iteration Operations
 // ========================================
 
-func (m *ArrayModule) foreach(interp *forthic.Interpreter) error {
+// FOREACH ( container code [options] -- [errors] )
+// Runs the code on each element of an array or value of a record. With
+// .push_error, a failing element doesn't stop the loop, and an array of each
+// element's error (or NULL) is pushed at the end.
+func (m *ArrayModule) foreach(interp *forthic.Interpreter, opts *forthic.WordOptions) error {
+	iter := iterOptionsOf(opts)
 	forthicCode := interp.StackPop()
 	items := interp.StackPop()
 
@@ -993,29 +1159,50 @@ func (m *ArrayModule) foreach(interp *forthic.Interpreter) error {
 	if !ok {
 		return nil
 	}
+	code := newCompiledCode(codeStr)
 
-	if items == nil {
+	keys, values, ok := entriesOf(items)
+	if !ok {
+		if iter.pushError {
+			interp.StackPush([]interface{}{})
+		}
 		return nil
 	}
 
-	if arr, ok := items.([]interface{}); ok {
-		for _, item := range arr {
-			interp.StackPush(item)
-			err := interp.Run(codeStr)
-			if err != nil {
-				return err
-			}
-		}
-	} else if rec, ok := items.(map[string]interface{}); ok {
-		for _, item := range rec {
-			interp.StackPush(item)
-			err := interp.Run(codeStr)
-			if err != nil {
-				return err
-			}
+	if iter.interps > 1 {
+		return m.foreachParallel(interp, code, keys, values, iter)
+	}
+
+	errs := make([]error, len(values))
+	for i, item := range values {
+		caught, err := code.runItem(interp, keys[i], item, iter)
+		if err != nil {
+			return err
 		}
+		errs[i] = caught
 	}
 
+	if iter.pushError {
+		interp.StackPush(errorValues(errs))
+	}
+	return nil
+}
+
+// foreachParallel runs FOREACH across forks, pushing what each run left on
+// its stack in element order
+func (m *ArrayModule) foreachParallel(interp *forthic.Interpreter, code *compiledCode, keys, values []interface{}, iter iterOptions) error {
+	outputs, errs, err := code.runParallel(interp, values, keys, iter)
+	if err != nil {
+		return err
+	}
+	for _, output := range outputs {
+		for _, value := range output {
+			interp.StackPush(value)
+		}
+	}
+	if iter.pushError {
+		interp.StackPush(errorValues(errs))
+	}
 	return nil
 }
 
@@ -1027,14 +1214,19 @@ func (m *ArrayModule) repeat(interp *forthic.Interpreter) error {
 	if !ok {
 		return nil
 	}
+	code := newCompiledCode(codeStr)
 
 	count := toInt(numTimes)
+	if err := interp.CheckCollectionSize(count); err != nil {
+		return err
+	}
+
 	for i := 0; i < count; i++ {
 		// Store item so we can push it back later
 		item := interp.StackPop()
 		interp.StackPush(item)
 
-		err := interp.Run(codeStr)
+		err := code.run(interp, i)
 		if err != nil {
 			return err
 		}
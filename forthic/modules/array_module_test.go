@@ -1,7 +1,12 @@
 package modules
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/forthix/forthic-go/forthic"
 )
@@ -442,6 +447,17 @@ func TestArray_Map(t *testing.T) {
 	}
 }
 
+func TestArray_MapCodeDefiningWords(t *testing.T) {
+	interp := NewStandardInterpreter()
+	err := interp.Run(`[1 2] "['x'] VARIABLES 5 x ! x @ +" MAP`)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if got := fmt.Sprint(interp.GetStack().Items()); got != "[[6 7]]" {
+		t.Errorf("Expected [[6 7]], got %s", got)
+	}
+}
+
 func TestArray_MapWithStrings(t *testing.T) {
 	interp := setupArrayInterpreter()
 	// Need to import string module for UPPERCASE
@@ -526,3 +542,462 @@ func TestArray_ReduceEmpty(t *testing.T) {
 		t.Errorf("Expected 42 (initial value), got %v", result)
 	}
 }
+
+func TestArray_MapEmptyWithUnknownWord(t *testing.T) {
+	interp := setupArrayInterpreter()
+	err := interp.Run(`[] "UNKNOWN_WORD" MAP`)
+	if err != nil {
+		t.Fatalf("Expected no error for code that never runs, got %v", err)
+	}
+}
+
+func TestArray_MapSeesRedefinedWords(t *testing.T) {
+	interp := setupArrayInterpreter()
+	err := interp.Run(`: DOUBLE 2 * ; [1 2] "DOUBLE" MAP  : DOUBLE 3 * ; [1 2] "DOUBLE" MAP`)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	second := interp.StackPop().([]interface{})
+	first := interp.StackPop().([]interface{})
+	if first[1].(float64) != 4.0 {
+		t.Errorf("Expected 4, got %v", first[1])
+	}
+	if second[1].(float64) != 6.0 {
+		t.Errorf("Expected 6 after redefining DOUBLE, got %v", second[1])
+	}
+}
+
+// ========================================
+// Benchmarks
+// ========================================
+
+func benchmarkRecords(n int) []interface{} {
+	records := make([]interface{}, n)
+	for i := 0; i < n; i++ {
+		records[i] = int64(i)
+	}
+	return records
+}
+
+func BenchmarkArray_Map(b *testing.B) {
+	interp := setupArrayInterpreter()
+	records := benchmarkRecords(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		interp.StackPush(records)
+		if err := interp.Run(`"2 * 1 +" MAP`); err != nil {
+			b.Fatal(err)
+		}
+		interp.StackPop()
+	}
+}
+
+func BenchmarkArray_MapUncompiled(b *testing.B) {
+	interp := setupArrayInterpreter()
+	records := benchmarkRecords(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, item := range records {
+			interp.StackPush(item)
+			if err := interp.Run(`2 * 1 +`); err != nil {
+				b.Fatal(err)
+			}
+			interp.StackPop()
+		}
+	}
+}
+
+func BenchmarkArray_Select(b *testing.B) {
+	interp := setupArrayInterpreter()
+	records := benchmarkRecords(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		interp.StackPush(records)
+		if err := interp.Run(`"5000 >" SELECT`); err != nil {
+			b.Fatal(err)
+		}
+		interp.StackPop()
+	}
+}
+
+func TestArray_RepeatStopsWhenDeadlineExceeded(t *testing.T) {
+	interp := setupArrayInterpreter()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := interp.RunContext(ctx, `1 "1 +" 100000000 <REPEAT`)
+	var deadline *forthic.DeadlineExceededError
+	if !errors.As(err, &deadline) {
+		t.Fatalf("Expected DeadlineExceededError, got %T: %v", err, err)
+	}
+	if deadline.Location == nil {
+		t.Error("Expected error to carry a location")
+	}
+}
+
+func TestArray_CollectionSizeLimit(t *testing.T) {
+	interp := setupArrayInterpreter()
+	interp.SetLimits(forthic.Limits{MaxCollectionSize: 3})
+
+	for _, code := range []string{
+		`[[1 2] [3 4]] FLATTEN`,
+		`[1 2] [3 4] UNION`,
+		`[1 2 3] 4 APPEND`,
+		`1 "1 +" 4 <REPEAT`,
+	} {
+		err := interp.Run(code)
+		var limitErr *forthic.ResourceLimitError
+		if !errors.As(err, &limitErr) {
+			t.Errorf("%s: expected ResourceLimitError, got %T: %v", code, err, err)
+		}
+	}
+}
+
+func TestArray_MapErrorStackTrace(t *testing.T) {
+	interp := NewStandardInterpreter()
+	err := interp.Run(`: GROUP [1 2 3] SWAP GROUPS-OF ; [2 1 0] "GROUP" MAP`)
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+
+	trace := forthic.GetStackTrace(err)
+	names := []string{}
+	for _, frame := range trace {
+		names = append(names, frame.Word)
+	}
+	if strings.Join(names, ",") != "GROUPS-OF,GROUP,<code>,MAP" {
+		t.Fatalf("Unexpected stack trace: %v", names)
+	}
+	if trace[2].Code != "GROUP" || trace[2].Item != 2 {
+		t.Errorf("Expected code frame for item 2, got %+v", trace[2])
+	}
+}
+
+func TestArray_MapErrorLocationInSourceFile(t *testing.T) {
+	interp := NewStandardInterpreter()
+	err := interp.RunSource("main.forthic", "[1 2 3]\n\"1 +\n NOPE\" MAP")
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+
+	var unknown *forthic.UnknownWordError
+	if !errors.As(err, &unknown) {
+		t.Fatalf("Expected UnknownWordError, got %T", err)
+	}
+	loc := unknown.Location
+	if loc.File != "main.forthic" || loc.Line != 3 || loc.Column != 2 {
+		t.Errorf("Expected main.forthic:3:2, got %s", loc)
+	}
+}
+
+// ========================================
+// Parallel MAP, SELECT and FOREACH
+// ========================================
+
+func TestArray_ParallelMapPreservesOrder(t *testing.T) {
+	interp := NewStandardInterpreter()
+	interp.StackPush(indexKeys(21))
+	err := interp.Run(`"DUP *" [.interps 4] ~> MAP`)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	result := interp.StackPop().([]interface{})
+	if len(result) != 21 {
+		t.Fatalf("Expected 21 results, got %d", len(result))
+	}
+	for i, value := range result {
+		if toInt(value) != i*i {
+			t.Errorf("Expected %d at %d, got %v", i*i, i, value)
+		}
+	}
+}
+
+func TestArray_ParallelSelectAndForeach(t *testing.T) {
+	interp := NewStandardInterpreter()
+	err := interp.Run(`
+		[1 2 3 4 5 6] "2 MOD 0 ==" [.interps 3] ~> SELECT
+		[1 2 3] "10 *" [.interps 2] ~> FOREACH
+	`)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if got := fmt.Sprint(interp.GetStack().Items()); got != "[[2 4 6] 10 20 30]" {
+		t.Errorf("Expected [[2 4 6] 10 20 30], got %s", got)
+	}
+}
+
+func TestArray_ParallelMapKeepsVariablesInForks(t *testing.T) {
+	interp := NewStandardInterpreter()
+	err := interp.Run(`["seen"] VARIABLES 0 seen ! [1 2 3] "seen !" [.interps 3] ~> FOREACH seen @`)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if result := interp.StackPop(); toInt(result) != 0 {
+		t.Errorf("Expected the parent's variable to stay 0, got %v", result)
+	}
+}
+
+func TestArray_ParallelMapErrorCancelsWorkers(t *testing.T) {
+	interp := NewStandardInterpreter()
+	failure := errors.New("bad element")
+	module := forthic.NewModule("work")
+	module.AddModuleWord("WORK", func(interp *forthic.Interpreter) error {
+		if toInt(interp.StackPop()) == 0 {
+			return failure
+		}
+		// Run until cancelled
+		for {
+			if err := interp.CheckContext(); err != nil {
+				return err
+			}
+			time.Sleep(time.Millisecond)
+		}
+	})
+	interp.ImportModule(module, "")
+
+	done := make(chan error)
+	go func() { done <- interp.Run(`[1 1 0 1] "WORK" [.interps 4] ~> MAP`) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the other workers to be cancelled")
+	}
+	if !errors.Is(err, failure) {
+		t.Fatalf("Expected the element's error, got %v", err)
+	}
+
+	trace := forthic.GetStackTrace(err)
+	found := false
+	for _, frame := range trace {
+		if frame.Word == "<code>" && frame.Item == 2 {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected a code frame for item 2, got %+v", trace)
+	}
+	if interp.GetStack().Length() != 0 {
+		t.Errorf("Expected an empty stack, got %v", interp.GetStack().Items())
+	}
+}
+
+func TestArray_ParallelMapKeepsLimits(t *testing.T) {
+	for _, code := range []string{`"1 +" MAP`, `"1 +" [.interps 2] ~> MAP`} {
+		interp := NewStandardInterpreter()
+		interp.StackPush(indexKeys(100))
+		interp.SetLimits(forthic.Limits{MaxInstructions: 50})
+
+		err := interp.Run(code)
+		var limitErr *forthic.ResourceLimitError
+		if !errors.As(err, &limitErr) || limitErr.Limit != forthic.LIMIT_INSTRUCTIONS {
+			t.Errorf("%s: expected the instruction limit, got %v", code, err)
+		}
+	}
+
+	interp := NewStandardInterpreter()
+	interp.SetLimits(forthic.Limits{MaxCallDepth: 3})
+	err := interp.Run(`: INNER 1 + ;  : OUTER "INNER" [.interps 2] ~> MAP ;  [1 2] OUTER`)
+	var limitErr *forthic.ResourceLimitError
+	if !errors.As(err, &limitErr) || limitErr.Limit != forthic.LIMIT_CALL_DEPTH {
+		t.Errorf("Expected the call depth limit, got %v", err)
+	}
+}
+
+func TestArray_ParallelMapStopsWhenCancelled(t *testing.T) {
+	interp := NewStandardInterpreter()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := interp.RunContext(ctx, `[1 2 3] '1 "1 +" 100000000 <REPEAT' [.interps 3] ~> MAP`)
+	var deadline *forthic.DeadlineExceededError
+	if !errors.As(err, &deadline) {
+		t.Fatalf("Expected DeadlineExceededError, got %T: %v", err, err)
+	}
+}
+
+// ========================================
+// Word Options
+// ========================================
+
+// newFailingInterpreter returns a standard interpreter with a CHECK word that
+// fails for the value 2 and otherwise leaves its value
+func newFailingInterpreter() *forthic.Interpreter {
+	interp := NewStandardInterpreter()
+	module := forthic.NewModule("check")
+	module.AddModuleWord("CHECK", func(interp *forthic.Interpreter) error {
+		value := interp.StackPeek()
+		if toInt(value) == 2 {
+			interp.StackPop()
+			return errors.New("two is not allowed")
+		}
+		return nil
+	})
+	interp.ImportModule(module, "")
+	return interp
+}
+
+func TestArray_OptionsWithKey(t *testing.T) {
+	interp := NewStandardInterpreter()
+	err := interp.Run(`
+		[10 20 30] "+" [.with_key TRUE] ~> MAP
+		[10 20] "+" [.with_key TRUE] ~> FOREACH
+		[["a" 1] ["b" 2]] REC 'POP "b" ==' [.with_key TRUE] ~> SELECT "b" REC@
+		[10 20] 0 "+ +" [.with_key TRUE] ~> REDUCE
+	`)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if got := fmt.Sprint(interp.GetStack().Items()); got != "[[10 21 32] 10 21 2 31]" {
+		t.Errorf("Expected [[10 21 32] 10 21 2 31], got %s", got)
+	}
+}
+
+func TestArray_MapRecord(t *testing.T) {
+	interp := NewStandardInterpreter()
+	err := interp.Run(`[["a" 1] ["b" 2]] REC "10 *" MAP "b" REC@`)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if result := interp.StackPop(); toInt(result) != 20 {
+		t.Errorf("Expected 20, got %v", result)
+	}
+}
+
+func TestArray_MapDepth(t *testing.T) {
+	interp := NewStandardInterpreter()
+	err := interp.Run(`[[1 2] [3 [4]]] "LENGTH" [.depth 1] ~> MAP`)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	// Only the elements one level down are mapped
+	if got := fmt.Sprint(interp.StackPop()); got != "[[0 0] [0 1]]" {
+		t.Errorf("Expected [[0 0] [0 1]], got %s", got)
+	}
+}
+
+func TestArray_MapPushError(t *testing.T) {
+	interp := newFailingInterpreter()
+	err := interp.Run(`"bottom" [1 2 3] "CHECK 10 *" [.push_error TRUE] ~> MAP`)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	errs := interp.StackPop().([]interface{})
+	result := interp.StackPop().([]interface{})
+	if got := fmt.Sprint(result); got != "[10 <nil> 30]" {
+		t.Errorf("Expected [10 <nil> 30], got %s", got)
+	}
+	if errs[0] != nil || errs[2] != nil {
+		t.Errorf("Expected no errors for 1 and 3, got %v", errs)
+	}
+	if err, ok := errs[1].(error); !ok || !strings.Contains(err.Error(), "Error executing word: CHECK") {
+		t.Errorf("Expected CHECK's error for 2, got %v", errs[1])
+	}
+	if got := fmt.Sprint(interp.GetStack().Items()); got != "[bottom]" {
+		t.Errorf("Expected the rest of the stack to be untouched, got %s", got)
+	}
+}
+
+func TestArray_ForeachPushError(t *testing.T) {
+	interp := newFailingInterpreter()
+	err := interp.Run(`[1 2 3] "CHECK" [.push_error TRUE] ~> FOREACH`)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	errs := interp.StackPop().([]interface{})
+	if errs[0] != nil || errs[1] == nil || errs[2] != nil {
+		t.Errorf("Expected an error for 2 only, got %v", errs)
+	}
+	if got := fmt.Sprint(interp.GetStack().Items()); got != "[1 3]" {
+		t.Errorf("Expected [1 3], got %s", got)
+	}
+}
+
+func TestArray_ParallelMapPushError(t *testing.T) {
+	interp := newFailingInterpreter()
+	err := interp.Run(`[1 2 3 4] "CHECK" [.push_error TRUE .interps 2] ~> MAP`)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	errs := interp.StackPop().([]interface{})
+	result := interp.StackPop().([]interface{})
+	if got := fmt.Sprint(result); got != "[1 <nil> 3 4]" {
+		t.Errorf("Expected [1 <nil> 3 4], got %s", got)
+	}
+	if errs[1] == nil || errs[0] != nil || errs[3] != nil {
+		t.Errorf("Expected an error for 2 only, got %v", errs)
+	}
+}
+
+func TestArray_PushErrorStillStopsIntentionally(t *testing.T) {
+	interp := NewStandardInterpreter()
+	module := forthic.NewModule("stop")
+	module.AddModuleWord("STOP", func(interp *forthic.Interpreter) error {
+		return forthic.NewIntentionalStopError("STOP")
+	})
+	interp.ImportModule(module, "")
+
+	err := interp.Run(`[1 2] "STOP" [.push_error TRUE] ~> MAP`)
+	if !errors.Is(err, forthic.ErrIntentionalStop) {
+		t.Fatalf("Expected an intentional stop, got %v", err)
+	}
+}
+
+func TestArray_SortComparator(t *testing.T) {
+	interp := NewStandardInterpreter()
+	err := interp.Run(`
+		[3 1 2] [.comparator "-1 *"] ~> SORT
+		[[["n" 2] ["id" "a"]] REC  [["n" 1] ["id" "b"]] REC] [.comparator "n"] ~> SORT "'id' REC@" MAP
+	`)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if got := fmt.Sprint(interp.GetStack().Items()); got != "[[3 2 1] [b a]]" {
+		t.Errorf("Expected [[3 2 1] [b a]], got %s", got)
+	}
+}
+
+func TestArray_FlattenDepth(t *testing.T) {
+	interp := NewStandardInterpreter()
+	err := interp.Run(`[[[1 2]] [3]] [.depth 1] ~> FLATTEN`)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if got := fmt.Sprint(interp.StackPop()); got != "[[1 2] 3]" {
+		t.Errorf("Expected [[1 2] 3], got %s", got)
+	}
+
+	err = interp.Run(`[[1]] [.depth 100000000000000000000.0] ~> FLATTEN`)
+	if !errors.Is(err, forthic.ErrInvalidOption) {
+		t.Fatalf("Expected an InvalidOptionError for a depth too large for an int, got %v", err)
+	}
+}
+
+func TestArray_TakePushRest(t *testing.T) {
+	interp := NewStandardInterpreter()
+	err := interp.Run(`[1 2 3 4] 3 [.push_rest TRUE] ~> TAKE`)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if got := fmt.Sprint(interp.GetStack().Items()); got != "[[1 2 3] [4]]" {
+		t.Errorf("Expected [[1 2 3] [4]], got %s", got)
+	}
+}
+
+func TestArray_InvalidOptions(t *testing.T) {
+	interp := NewStandardInterpreter()
+	err := interp.Run(`[1 2] "1 +" [.depth "deep"] ~> MAP`)
+	if !errors.Is(err, forthic.ErrInvalidOption) {
+		t.Fatalf("Expected an InvalidOptionError, got %v", err)
+	}
+
+	err = interp.Run(`[1 2] [.push_rest TRUE] ~> SORT`)
+	var optionErr *forthic.InvalidOptionError
+	if !errors.As(err, &optionErr) || optionErr.Word != "SORT" {
+		t.Fatalf("Expected an InvalidOptionError for SORT, got %v", err)
+	}
+}
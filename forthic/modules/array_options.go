@@ -0,0 +1,139 @@
+package modules
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/forthix/forthic-go/forthic"
+)
+
+// Options for array words
+//
+// Array words take options with ~>, as in the other Forthic runtimes:
+//
+//	[1 2 3] "+" [.with_key TRUE] ~> MAP
+//
+// Each word declares the options it accepts with an OptionSchema, so
+// unknown options and values of the wrong type are rejected.
+
+var (
+	withKeyOption = forthic.OptionSpec{
+		Name: "with_key", Type: forthic.OptionBool, Default: false,
+		Doc: "Push each element's index (or record key) before the element",
+	}
+	pushErrorOption = forthic.OptionSpec{
+		Name: "push_error", Type: forthic.OptionBool, Default: false,
+		Doc: "Don't stop at a failing element; push an array of each element's error (or NULL) at the end",
+	}
+	interpsOption = forthic.OptionSpec{
+		Name: "interps", Type: forthic.OptionInt, Default: int64(1),
+		Doc: "Number of interpreters to run the code on in parallel",
+	}
+)
+
+var (
+	mapOptions = forthic.OptionSchema{withKeyOption, pushErrorOption, interpsOption, {
+		Name: "depth", Type: forthic.OptionInt, Default: int64(0),
+		Doc: "Map the elements of arrays and records nested this many levels down",
+	}}
+	selectOptions  = forthic.OptionSchema{withKeyOption, interpsOption}
+	foreachOptions = forthic.OptionSchema{withKeyOption, pushErrorOption, interpsOption}
+	withKeyOptions = forthic.OptionSchema{withKeyOption}
+	sortOptions    = forthic.OptionSchema{{
+		Name: "comparator", Type: forthic.OptionString,
+		Doc: "Sort by the value this Forthic code leaves for each element, or by this record field",
+	}}
+	flattenOptions = forthic.OptionSchema{{
+		Name: "depth", Type: forthic.OptionInt,
+		Doc: "Flatten only this many levels of nesting",
+	}}
+	takeOptions = forthic.OptionSchema{{
+		Name: "push_rest", Type: forthic.OptionBool, Default: false,
+		Doc: "Push the elements not taken after the ones taken",
+	}}
+)
+
+// iterOptions control how a higher-order word runs its code for each element
+type iterOptions struct {
+	withKey   bool
+	pushError bool
+	interps   int
+}
+
+func iterOptionsOf(opts *forthic.WordOptions) iterOptions {
+	interps := toInt(opts.Get("interps", 1))
+	if interps < 1 {
+		interps = 1
+	}
+	return iterOptions{
+		withKey:   isTruthy(opts.Get("with_key", false)),
+		pushError: isTruthy(opts.Get("push_error", false)),
+		interps:   interps,
+	}
+}
+
+// entriesOf returns the keys and values of an array or record
+// Arrays are keyed by index; records are visited in key order.
+func entriesOf(container interface{}) (keys []interface{}, values []interface{}, ok bool) {
+	if arr, ok := container.([]interface{}); ok {
+		return indexKeys(len(arr)), arr, true
+	}
+	if rec, ok := container.(map[string]interface{}); ok {
+		names := make([]string, 0, len(rec))
+		for name := range rec {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		keys = make([]interface{}, len(names))
+		values = make([]interface{}, len(names))
+		for i, name := range names {
+			keys[i] = name
+			values[i] = rec[name]
+		}
+		return keys, values, true
+	}
+	return nil, nil, false
+}
+
+// catchable reports whether .push_error may collect err
+// Intentional stops, cancellation and resource limits always stop the word.
+func catchable(err error) bool {
+	return !errors.Is(err, forthic.ErrIntentionalStop) &&
+		!errors.Is(err, forthic.ErrResourceLimit) &&
+		!errors.Is(err, forthic.ErrCancelled) &&
+		!errors.Is(err, forthic.ErrDeadlineExceeded)
+}
+
+// runItem runs the code for one element, pushing its key first if .with_key
+// is set
+//
+// With .push_error, a catchable error is returned as caught rather than err,
+// after restoring the stack to its depth before the element was pushed.
+func (c *compiledCode) runItem(interp *forthic.Interpreter, key, item interface{}, opts iterOptions) (caught error, err error) {
+	depth := interp.GetStack().Length()
+	if opts.withKey {
+		interp.StackPush(key)
+	}
+	interp.StackPush(item)
+
+	err = c.run(interp, key)
+	if err == nil || !opts.pushError || !catchable(err) {
+		return nil, err
+	}
+	for interp.GetStack().Length() > depth {
+		interp.StackPop()
+	}
+	return err, nil
+}
+
+// errorValues converts per-element errors into the array .push_error pushes
+func errorValues(errs []error) []interface{} {
+	values := make([]interface{}, len(errs))
+	for i, err := range errs {
+		if err != nil {
+			values[i] = err
+		}
+	}
+	return values
+}
@@ -53,6 +53,9 @@ func (m *CoreModule) registerWords() {
 	// Options
 	m.AddModuleWord("~>", m.toOptions)
 
+	// Memos
+	m.AddModuleWord("INVALIDATE-MEMOS", m.invalidateMemos)
+
 	// Profiling
 	m.AddModuleWord("PROFILE-START", m.profileStart)
 	m.AddModuleWord("PROFILE-END", m.profileEnd)
@@ -64,8 +67,8 @@ func (m *CoreModule) registerWords() {
 	m.AddModuleWord("END-LOG", m.endLog)
 
 	// String operations
-	m.AddModuleWord("INTERPOLATE", m.interpolate)
-	m.AddModuleWord("PRINT", m.print)
+	m.AddModuleWordWithOptions("INTERPOLATE", interpolateOptions, m.interpolate)
+	m.AddModuleWordWithOptions("PRINT", interpolateOptions, m.print)
 
 	// Debug
 	m.AddModuleWord("PEEK!", m.peek)
@@ -157,7 +160,7 @@ func (m *CoreModule) set(interp *forthic.Interpreter) error {
 		varObj = variable.(*forthic.Variable)
 	}
 
-	varObj.SetValue(value)
+	interp.SetVariable(varObj, value)
 	return nil
 }
 
@@ -200,7 +203,7 @@ func (m *CoreModule) setGet(interp *forthic.Interpreter) error {
 		varObj = variable.(*forthic.Variable)
 	}
 
-	varObj.SetValue(value)
+	interp.SetVariable(varObj, value)
 	interp.StackPush(varObj.GetValue())
 	return nil
 }
@@ -321,46 +324,50 @@ func (m *CoreModule) toOptions(interp *forthic.Interpreter) error {
 }
 
 // ========================================
-// Profiling (Placeholder implementations)
+// Memos
+// ========================================
+
+func (m *CoreModule) invalidateMemos(interp *forthic.Interpreter) error {
+	interp.InvalidateMemos()
+	return nil
+}
+
+// ========================================
+// Profiling
 // ========================================
 
 func (m *CoreModule) profileStart(interp *forthic.Interpreter) error {
-	// TODO: Implement profiling in interpreter
+	interp.StartProfiling()
 	return nil
 }
 
 func (m *CoreModule) profileEnd(interp *forthic.Interpreter) error {
-	// TODO: Implement profiling in interpreter
+	interp.StopProfiling()
 	return nil
 }
 
 func (m *CoreModule) profileTimestamp(interp *forthic.Interpreter) error {
 	label := interp.StackPop()
-	_ = label // TODO: Implement profiling
+	interp.AddProfileTimestamp(toString(label))
 	return nil
 }
 
 func (m *CoreModule) profileData(interp *forthic.Interpreter) error {
-	// TODO: Implement profiling
-	result := map[string]interface{}{
-		"word_counts": []interface{}{},
-		"timestamps":  []interface{}{},
-	}
-	interp.StackPush(result)
+	interp.StackPush(interp.ProfileData())
 	return nil
 }
 
 // ========================================
-// Logging (Placeholder implementations)
+// Logging
 // ========================================
 
 func (m *CoreModule) startLog(interp *forthic.Interpreter) error {
-	// TODO: Implement logging in interpreter
+	interp.StartLog()
 	return nil
 }
 
 func (m *CoreModule) endLog(interp *forthic.Interpreter) error {
-	// TODO: Implement logging in interpreter
+	interp.EndLog()
 	return nil
 }
 
@@ -368,48 +375,29 @@ func (m *CoreModule) endLog(interp *forthic.Interpreter) error {
 // String Operations
 // ========================================
 
-func (m *CoreModule) interpolate(interp *forthic.Interpreter) error {
-	// Pop options if present
-	topVal := interp.StackPop()
-	var str string
-	var opts *forthic.WordOptions
-
-	// Check if we have options
-	if optsVal, ok := topVal.(*forthic.WordOptions); ok {
-		opts = optsVal
-		str, _ = interp.StackPop().(string)
-	} else {
-		str, _ = topVal.(string)
-		opts, _ = forthic.NewWordOptions([]interface{}{})
-	}
+// interpolateOptions are the options INTERPOLATE and PRINT accept
+var interpolateOptions = forthic.OptionSchema{
+	{Name: "separator", Type: forthic.OptionString, Default: ", ", Doc: "Text between array items"},
+	{Name: "null_text", Type: forthic.OptionString, Default: "null", Doc: "Text for NULL values"},
+	{Name: "json", Type: forthic.OptionBool, Default: false, Doc: "Format values as JSON"},
+}
 
-	separator, _ := opts.Get("separator", ", ").(string)
-	nullText, _ := opts.Get("null_text", "null").(string)
-	useJSON, _ := opts.Get("json", false).(bool)
+func (m *CoreModule) interpolate(interp *forthic.Interpreter, opts *forthic.WordOptions) error {
+	str, _ := interp.StackPop().(string)
+	separator := opts.Get("separator").(string)
+	nullText := opts.Get("null_text").(string)
+	useJSON := opts.Get("json").(bool)
 
 	result := interpolateString(interp, str, separator, nullText, useJSON)
 	interp.StackPush(result)
 	return nil
 }
 
-func (m *CoreModule) print(interp *forthic.Interpreter) error {
-	// Pop options if present
-	topVal := interp.StackPop()
-	var value interface{}
-	var opts *forthic.WordOptions
-
-	// Check if we have options
-	if optsVal, ok := topVal.(*forthic.WordOptions); ok {
-		opts = optsVal
-		value = interp.StackPop()
-	} else {
-		value = topVal
-		opts, _ = forthic.NewWordOptions([]interface{}{})
-	}
-
-	separator, _ := opts.Get("separator", ", ").(string)
-	nullText, _ := opts.Get("null_text", "null").(string)
-	useJSON, _ := opts.Get("json", false).(bool)
+func (m *CoreModule) print(interp *forthic.Interpreter, opts *forthic.WordOptions) error {
+	value := interp.StackPop()
+	separator := opts.Get("separator").(string)
+	nullText := opts.Get("null_text").(string)
+	useJSON := opts.Get("json").(bool)
 
 	var result string
 	if str, ok := value.(string); ok {
@@ -1,8 +1,12 @@
 package modules
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/forthix/forthic-go/forthic"
 )
@@ -40,6 +44,33 @@ func TestCore_POP(t *testing.T) {
 	}
 }
 
+func TestCore_POP_EmptyStack(t *testing.T) {
+	interp := setupCoreInterpreter()
+
+	err := interp.Run("POP")
+	if err == nil {
+		t.Fatal("Expected stack underflow error")
+	}
+
+	var underflow *forthic.StackUnderflowError
+	if !errors.As(err, &underflow) {
+		t.Fatalf("Expected StackUnderflowError, got %T: %v", err, err)
+	}
+	if underflow.Word != "POP" {
+		t.Errorf("Expected underflow in POP, got %q", underflow.Word)
+	}
+}
+
+func TestCore_INTERPRET_StackUnderflow(t *testing.T) {
+	interp := setupCoreInterpreter()
+
+	err := interp.Run(`"SWAP" INTERPRET`)
+	var underflow *forthic.StackUnderflowError
+	if !errors.As(err, &underflow) {
+		t.Errorf("Expected StackUnderflowError, got %T: %v", err, err)
+	}
+}
+
 func TestCore_DUP(t *testing.T) {
 	interp := setupCoreInterpreter()
 
@@ -448,6 +479,21 @@ func TestCore_INTERPOLATE_WithOptions(t *testing.T) {
 	}
 }
 
+func TestCore_INTERPOLATE_InvalidOptions(t *testing.T) {
+	interp := setupCoreInterpreter()
+
+	err := interp.Run(`"Items" [.separater " | "] ~> INTERPOLATE`)
+	var optionErr *forthic.InvalidOptionError
+	if !errors.As(err, &optionErr) || optionErr.Option != "separater" {
+		t.Fatalf("Expected an InvalidOptionError for .separater, got %v", err)
+	}
+
+	err = interp.Run(`"Items" [.json "yes"] ~> PRINT`)
+	if !errors.Is(err, forthic.ErrInvalidOption) {
+		t.Fatalf("Expected an InvalidOptionError for .json, got %v", err)
+	}
+}
+
 func TestCore_INTERPOLATE_EscapedDots(t *testing.T) {
 	interp := setupCoreInterpreter()
 
@@ -477,46 +523,131 @@ func TestCore_INTERPOLATE_NullText(t *testing.T) {
 }
 
 // ========================================
-// Profiling (Placeholder tests)
+// Profiling
 // ========================================
 
 func TestCore_Profiling(t *testing.T) {
 	interp := setupCoreInterpreter()
 
-	// Test basic profiling operations
-	err := interp.Run(`PROFILE-START PROFILE-END`)
+	err := interp.Run(`
+		PROFILE-START
+		1 2 + POP
+		1 2 + POP
+		"middle" PROFILE-TIMESTAMP
+		PROFILE-END
+		PROFILE-DATA
+	`)
 	if err != nil {
 		t.Fatalf("Error running code: %v", err)
 	}
 
-	err = interp.Run(`"test" PROFILE-TIMESTAMP`)
+	result := interp.StackPop().(map[string]interface{})
+
+	wordCounts := result["word_counts"].([]interface{})
+	counts := make(map[string]int64)
+	for _, entry := range wordCounts {
+		rec := entry.(map[string]interface{})
+		counts[rec["word"].(string)] = rec["count"].(int64)
+	}
+	if counts["+"] != 2 || counts["POP"] != 2 {
+		t.Errorf("Expected + and POP to be counted twice, got %v", counts)
+	}
+	if first := wordCounts[0].(map[string]interface{}); first["count"].(int64) < 2 {
+		t.Errorf("Expected word counts sorted by count, got %v", wordCounts)
+	}
+
+	timestamps := result["timestamps"].([]interface{})
+	labels := []string{}
+	for _, entry := range timestamps {
+		rec := entry.(map[string]interface{})
+		labels = append(labels, rec["label"].(string))
+		if _, ok := rec["delta"].(float64); !ok {
+			t.Errorf("Expected delta in timestamp record, got %v", rec)
+		}
+	}
+	if strings.Join(labels, ",") != "START,middle,END" {
+		t.Errorf("Expected START,middle,END timestamps, got %v", labels)
+	}
+}
+
+func TestCore_ProfilingOff(t *testing.T) {
+	interp := setupCoreInterpreter()
+
+	err := interp.Run(`1 2 + POP "ignored" PROFILE-TIMESTAMP PROFILE-DATA`)
 	if err != nil {
 		t.Fatalf("Error running code: %v", err)
 	}
 
-	err = interp.Run(`PROFILE-DATA`)
+	result := interp.StackPop().(map[string]interface{})
+	if len(result["word_counts"].([]interface{})) != 0 {
+		t.Errorf("Expected no word counts when not profiling, got %v", result["word_counts"])
+	}
+	if len(result["timestamps"].([]interface{})) != 0 {
+		t.Errorf("Expected no timestamps when not profiling, got %v", result["timestamps"])
+	}
+}
+
+// ========================================
+// Logging
+// ========================================
+
+func TestCore_Logging(t *testing.T) {
+	interp := setupCoreInterpreter()
+	var buf bytes.Buffer
+	interp.SetLogWriter(&buf)
+
+	err := interp.Run(`1 START-LOG 2 + END-LOG 3`)
 	if err != nil {
 		t.Fatalf("Error running code: %v", err)
 	}
 
-	result := interp.StackPop()
-	if result == nil {
-		t.Error("Expected profiling data to be returned")
+	output := buf.String()
+	for _, expected := range []string{"word=2", "word=+", "word=END-LOG", "stack_before=[1]", "stack_after=[3]"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected log to contain %q, got:\n%s", expected, output)
+		}
+	}
+	if strings.Contains(output, "word=3") {
+		t.Errorf("Expected no records after END-LOG, got:\n%s", output)
 	}
 }
 
 // ========================================
-// Logging (Placeholder tests)
+// Memos
 // ========================================
 
-func TestCore_Logging(t *testing.T) {
+func TestCore_MemoTTLAndInvalidate(t *testing.T) {
 	interp := setupCoreInterpreter()
 
-	// Test basic logging operations
-	err := interp.Run(`START-LOG END-LOG`)
+	err := interp.Run(`
+		["count"] VARIABLES 0 count !
+		[.ttl "1h"] ~> @: COUNTED  count @ 1 + count !  count @ ;
+		COUNTED COUNTED
+	`)
 	if err != nil {
 		t.Fatalf("Error running code: %v", err)
 	}
+	memos := interp.Memos()
+	if len(memos) != 1 || memos[0].Name != "COUNTED" || memos[0].TTL != time.Hour || !memos[0].Cached {
+		t.Fatalf("Unexpected memos: %+v", memos)
+	}
+
+	if err := interp.Run(`INVALIDATE-MEMOS COUNTED`); err != nil {
+		t.Fatalf("Error running code: %v", err)
+	}
+	items := interp.GetStack().Items()
+	if len(items) != 3 || fmt.Sprint(items) != "[1 1 2]" {
+		t.Errorf("Expected [1 1 2], got %v", items)
+	}
+}
+
+func TestCore_MemoUnknownOption(t *testing.T) {
+	interp := setupCoreInterpreter()
+
+	err := interp.Run(`[.ttl 5 .size 3] ~> @: M 1 ;`)
+	if err == nil || !strings.Contains(err.Error(), "Unknown memo option: .size") {
+		t.Errorf("Expected unknown option error, got %v", err)
+	}
 }
 
 // ========================================
@@ -572,3 +703,16 @@ func TestCore_StackManipulation(t *testing.T) {
 		t.Errorf("Expected bottom to be 1, got %v", items[0])
 	}
 }
+
+func TestCore_INTERPRET_RecursionLimit(t *testing.T) {
+	interp := setupCoreInterpreter()
+
+	err := interp.Run(`: RECURSE "RECURSE" INTERPRET ; RECURSE`)
+	var limitErr *forthic.ResourceLimitError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("Expected ResourceLimitError, got %T: %v", err, err)
+	}
+	if limitErr.Limit != forthic.LIMIT_CALL_DEPTH {
+		t.Errorf("Expected %s, got %s", forthic.LIMIT_CALL_DEPTH, limitErr.Limit)
+	}
+}
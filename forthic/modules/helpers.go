@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/forthix/forthic-go/forthic"
 )
 
 // Common helper functions shared across modules
@@ -131,3 +133,50 @@ func toInt(val interface{}) int {
 		return 0
 	}
 }
+
+// compiledCode runs a string of Forthic code, compiling it on first use
+//
+// Higher-order words (MAP, SELECT, ...) run the same code once per element.
+// Compiling lazily avoids re-parsing the code for every element while still
+// not reporting errors for code that never runs (e.g. MAP over an empty array).
+type compiledCode struct {
+	code string
+	word *forthic.DefinitionWord
+}
+
+func newCompiledCode(code string) *compiledCode {
+	return &compiledCode{code: code}
+}
+
+// run runs the code for one element, identified by its index or key
+// Errors gain a stack frame naming the code string and the element.
+func (c *compiledCode) run(interp *forthic.Interpreter, item interface{}) error {
+	location := interp.CurrentLocation()
+	if err := interp.CheckContext(); err != nil {
+		return err
+	}
+	if err := c.compile(interp, location, item); err != nil {
+		return err
+	}
+	if err := c.word.Execute(interp); err != nil {
+		return forthic.AddStackFrame(err, c.frame(location, item))
+	}
+	return nil
+}
+
+// compile compiles the code if it hasn't been already
+func (c *compiledCode) compile(interp *forthic.Interpreter, location *forthic.CodeLocation, item interface{}) error {
+	if c.word != nil {
+		return nil
+	}
+	word, err := interp.Compile(c.code)
+	if err != nil {
+		return forthic.AddStackFrame(err, c.frame(location, item))
+	}
+	c.word = word
+	return nil
+}
+
+func (c *compiledCode) frame(location *forthic.CodeLocation, item interface{}) forthic.StackFrame {
+	return forthic.StackFrame{Word: "<code>", Location: location, Code: c.code, Item: item}
+}
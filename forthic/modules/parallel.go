@@ -0,0 +1,127 @@
+package modules
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/forthix/forthic-go/forthic"
+)
+
+// Parallel execution of higher-order words
+//
+// MAP, SELECT and FOREACH accept an .interps option:
+//
+//	[1 2 3 4] "FETCH-PAGE" [.interps 4] ~> MAP
+//
+// The elements are spread across that many forks of the interpreter (see
+// forthic.Interpreter.Fork), each running on its own goroutine. Each run
+// of the code starts with only its element (and key, with .with_key) on the
+// stack, and changes to variables and definitions made by the code stay in
+// its fork. Results are returned in element order. If the code fails for
+// any element, the other runs are cancelled and the error is returned with a
+// stack frame naming the element, unless .push_error collects the errors.
+
+// indexKeys returns the indexes of an array of length n, for stack frames
+func indexKeys(n int) []interface{} {
+	keys := make([]interface{}, n)
+	for i := range keys {
+		keys[i] = i
+	}
+	return keys
+}
+
+// runParallel runs the code once per item on up to opts.interps forks
+// It returns what each run left on the stack, in item order. keys identify
+// the items in stack frames (indexes for arrays, keys for records), and are
+// pushed before the items with .with_key. With .push_error, errors are
+// returned per item instead of cancelling the other runs.
+func (c *compiledCode) runParallel(interp *forthic.Interpreter, items []interface{}, keys []interface{}, opts iterOptions) ([][]interface{}, []error, error) {
+	location := interp.CurrentLocation()
+	if len(items) == 0 {
+		return [][]interface{}{}, []error{}, nil
+	}
+	if err := interp.CheckContext(); err != nil {
+		return nil, nil, err
+	}
+	if err := c.compile(interp, location, keys[0]); err != nil {
+		return nil, nil, err
+	}
+
+	numWorkers := min(opts.interps, len(items))
+	forks := make([]*forthic.Interpreter, numWorkers)
+	for i := range forks {
+		forks[i] = interp.Fork()
+		forks[i].GetStack().Clear()
+	}
+
+	ctx, cancel := context.WithCancel(interp.Context())
+	defer cancel()
+
+	outputs := make([][]interface{}, len(items))
+	errs := make([]error, len(items))
+	next := int64(-1)
+	var firstErr error
+	var failOnce sync.Once
+	var wg sync.WaitGroup
+	for _, fork := range forks {
+		wg.Add(1)
+		go func(fork *forthic.Interpreter) {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				index := int(atomic.AddInt64(&next, 1))
+				if index >= len(items) {
+					return
+				}
+
+				if opts.withKey {
+					fork.StackPush(keys[index])
+				}
+				fork.StackPush(items[index])
+				if err := fork.ExecuteContext(ctx, c.word); err != nil {
+					err = forthic.AddStackFrame(err, c.frame(location, keys[index]))
+					if opts.pushError && catchable(err) {
+						errs[index] = err
+						fork.GetStack().Clear()
+						continue
+					}
+					failOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					return
+				}
+				outputs[index] = fork.GetStack().Items()
+				fork.GetStack().Clear()
+			}
+		}(fork)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, nil, firstErr
+	}
+	// Workers stop without an error if the interpreter's own context is done
+	if err := interp.CheckContext(); err != nil {
+		return nil, nil, err
+	}
+	return outputs, errs, nil
+}
+
+// resultsOf returns the value each run left on top of its stack
+// Items whose run failed (with .push_error) have a nil result.
+func (c *compiledCode) resultsOf(interp *forthic.Interpreter, outputs [][]interface{}, errs []error, keys []interface{}) ([]interface{}, error) {
+	results := make([]interface{}, len(outputs))
+	for i, output := range outputs {
+		if errs[i] != nil {
+			continue
+		}
+		if len(output) == 0 {
+			location := interp.CurrentLocation()
+			err := forthic.NewStackUnderflowError().WithLocation(location)
+			return nil, forthic.AddStackFrame(err, c.frame(location, keys[i]))
+		}
+		results[i] = output[len(output)-1]
+	}
+	return results, nil
+}
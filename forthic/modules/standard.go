@@ -0,0 +1,70 @@
+package modules
+
+import (
+	"github.com/forthix/forthic-go/forthic"
+)
+
+// standardModuleFactories lists the standard library modules in import order
+//
+// Modules imported later take precedence over earlier ones, matching the
+// order used by the other Forthic runtimes.
+var standardModuleFactories = []struct {
+	name    string
+	factory forthic.ModuleFactory
+}{
+	{"core", func() *forthic.Module { return NewCoreModule().Module }},
+	{"array", func() *forthic.Module { return NewArrayModule().Module }},
+	{"record", func() *forthic.Module { return NewRecordModule().Module }},
+	{"string", func() *forthic.Module { return NewStringModule().Module }},
+	{"math", func() *forthic.Module { return NewMathModule().Module }},
+	{"boolean", func() *forthic.Module { return NewBooleanModule().Module }},
+	{"datetime", func() *forthic.Module { return NewDateTimeModule().Module }},
+	{"json", func() *forthic.Module { return NewJSONModule().Module }},
+}
+
+func init() {
+	for _, entry := range standardModuleFactories {
+		forthic.RegisterModuleFactory(entry.name, entry.factory)
+	}
+}
+
+// StandardModuleNames returns the names of the standard library modules in import order
+func StandardModuleNames() []string {
+	names := make([]string, len(standardModuleFactories))
+	for i, entry := range standardModuleFactories {
+		names[i] = entry.name
+	}
+	return names
+}
+
+// NewStandardInterpreter creates an Interpreter with the standard library imported
+//
+// All eight standard modules are imported unprefixed, followed by any
+// additional modules, which take precedence over standard words. As with
+// forthic.NewInterpreter, panics if an additional module's Forthic code fails;
+// use NewStandardInterpreterWithModules to handle the error instead.
+func NewStandardInterpreter(modules ...*forthic.Module) *forthic.Interpreter {
+	interp, err := NewStandardInterpreterWithModules(modules...)
+	if err != nil {
+		panic(err)
+	}
+	return interp
+}
+
+// NewStandardInterpreterWithModules creates an Interpreter with the standard
+// library and modules imported, as NewStandardInterpreter does, returning an
+// error if a module's Forthic code fails
+func NewStandardInterpreterWithModules(modules ...*forthic.Module) (*forthic.Interpreter, error) {
+	interp := forthic.NewInterpreter()
+	for _, entry := range standardModuleFactories {
+		if err := interp.ImportModule(entry.factory(), ""); err != nil {
+			return nil, err
+		}
+	}
+	for _, module := range modules {
+		if err := interp.ImportModule(module, ""); err != nil {
+			return nil, err
+		}
+	}
+	return interp, nil
+}
@@ -0,0 +1,206 @@
+package modules
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/forthix/forthic-go/forthic"
+)
+
+func TestStandard_NewStandardInterpreter(t *testing.T) {
+	interp := NewStandardInterpreter()
+
+	err := interp.Run(`[1 2 3] "2 *" MAP  "a-b" "-" SPLIT  [TRUE FALSE] OR`)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if interp.GetStack().Length() != 3 {
+		t.Fatalf("Expected 3 items on stack, got %d", interp.GetStack().Length())
+	}
+}
+
+func TestStandard_AllModulesRegistered(t *testing.T) {
+	interp := NewStandardInterpreter()
+	for _, name := range StandardModuleNames() {
+		if _, err := interp.FindModule(name); err != nil {
+			t.Errorf("Expected module %s to be registered: %v", name, err)
+		}
+	}
+}
+
+func TestStandard_ExtraModulesTakePrecedence(t *testing.T) {
+	custom := forthic.NewModule("custom")
+	custom.AddModuleWord("LENGTH", func(interp *forthic.Interpreter) error {
+		interp.StackPop()
+		interp.StackPush("custom")
+		return nil
+	})
+
+	interp := NewStandardInterpreter(custom)
+	err := interp.Run(`[1 2 3] LENGTH`)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if result := interp.StackPop(); result != "custom" {
+		t.Errorf("Expected custom LENGTH to win, got %v", result)
+	}
+}
+
+func TestStandard_WithModulesReturnsModuleCodeError(t *testing.T) {
+	interp, err := NewStandardInterpreterWithModules(forthic.NewModule("broken", "[1 2] NOPE"))
+	if interp != nil {
+		t.Errorf("Expected no interpreter, got %v", interp)
+	}
+	var moduleErr *forthic.ModuleError
+	if !errors.As(err, &moduleErr) || moduleErr.Module != "broken" {
+		t.Fatalf("Expected a ModuleError naming broken, got %v", err)
+	}
+}
+
+func TestStandard_UseModulesFromFactory(t *testing.T) {
+	interp := forthic.NewInterpreter(NewCoreModule().Module)
+
+	err := interp.Run(`[["math" "m"]] USE-MODULES  2 3 m.+`)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if result := interp.StackPop(); result != 5.0 {
+		t.Errorf("Expected 5, got %v", result)
+	}
+}
+
+func TestStandard_UseModulesInterpreterFactory(t *testing.T) {
+	interp := forthic.NewInterpreter(NewCoreModule().Module)
+	interp.RegisterModuleFactory("greeting", func() *forthic.Module {
+		module := forthic.NewModule("greeting")
+		module.AddModuleWord("HELLO", func(interp *forthic.Interpreter) error {
+			interp.StackPush("hello")
+			return nil
+		})
+		return module
+	})
+
+	err := interp.Run(`["greeting"] USE-MODULES HELLO`)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if result := interp.StackPop(); result != "hello" {
+		t.Errorf("Expected hello, got %v", result)
+	}
+}
+
+func TestStandard_UseModulesUnknown(t *testing.T) {
+	interp := forthic.NewInterpreter(NewCoreModule().Module)
+
+	err := interp.Run(`["no-such-module"] USE-MODULES`)
+	var unknown *forthic.UnknownModuleError
+	if !errors.As(err, &unknown) {
+		t.Errorf("Expected UnknownModuleError, got %T: %v", err, err)
+	}
+}
+
+func TestStandard_UseModulesFromFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"lib/reports.forthic": {Data: []byte(`: TOTAL 0 "+" REDUCE ; : DOUBLED "2 *" MAP ;`)},
+	}
+	interp := NewStandardInterpreter()
+	interp.SetModuleLoader(forthic.NewFSModuleLoader(fsys, "lib"))
+
+	err := interp.Run(`[["reports" "r"]] USE-MODULES [1 2 3] r.DOUBLED DUP r.TOTAL`)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if total := interp.StackPop(); fmt.Sprint(total) != "12" {
+		t.Errorf("Expected 12, got %v", total)
+	}
+	if result := interp.StackPop(); fmt.Sprint(result) != "[2 4 6]" {
+		t.Errorf("Expected [2 4 6], got %v", result)
+	}
+}
+
+func TestStandard_SnapshotRestore(t *testing.T) {
+	interp := NewStandardInterpreter()
+	err := interp.Run(`["count"] VARIABLES 5 count ! : BUMPED count @ 1 + ;`)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	data, err := interp.MarshalSnapshot(forthic.SnapshotOptions{})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	restored := NewStandardInterpreter()
+	if err := restored.UnmarshalSnapshot(data); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if err := restored.Run(`BUMPED`); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if result := restored.StackPop(); fmt.Sprint(result) != "6" {
+		t.Errorf("Expected 6, got %v", result)
+	}
+}
+
+func TestStandard_SnapshotRestoresMemoTTL(t *testing.T) {
+	interp := NewStandardInterpreter()
+	if err := interp.Run(`[.ttl 300] ~> @: RATES 1 ;`); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	data, err := interp.MarshalSnapshot(forthic.SnapshotOptions{})
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	restored := NewStandardInterpreter()
+	if err := restored.UnmarshalSnapshot(data); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	memos := restored.Memos()
+	if len(memos) != 1 || memos[0].Name != "RATES" || memos[0].TTL != 300*time.Second {
+		t.Errorf("Expected RATES with a 5m TTL, got %+v", memos)
+	}
+}
+
+func TestStandard_ForkConcurrent(t *testing.T) {
+	interp := NewStandardInterpreter()
+	err := interp.Run(`["total"] VARIABLES 0 total ! : ADD-ALL 0 "+" REDUCE total ! ; : SQUARES "DUP *" MAP ;`)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	const numForks = 4
+	forks := make([]*forthic.Interpreter, numForks)
+	for j := range forks {
+		forks[j] = interp.Fork()
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, numForks)
+	for j, fork := range forks {
+		wg.Add(1)
+		go func(j int, fork *forthic.Interpreter) {
+			defer wg.Done()
+			errs[j] = fork.Run(fmt.Sprintf(`[1 2 %d] SQUARES ADD-ALL total @`, j))
+		}(j, fork)
+	}
+	wg.Wait()
+
+	for j, fork := range forks {
+		if errs[j] != nil {
+			t.Fatalf("Fork %d: %v", j, errs[j])
+		}
+		if result := fork.StackPop(); fmt.Sprint(result) != fmt.Sprint(5+j*j) {
+			t.Errorf("Fork %d: expected %d, got %v", j, 5+j*j, result)
+		}
+	}
+	if err := interp.Run(`total @`); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if result := interp.StackPop(); fmt.Sprint(result) != "0" {
+		t.Errorf("Expected parent's total to stay 0, got %v", result)
+	}
+}
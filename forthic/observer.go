@@ -0,0 +1,128 @@
+package forthic
+
+// StackReader - Read-only view of the interpreter's data stack
+//
+// Observers receive a StackReader rather than the Stack itself so that they
+// can inspect, but not modify, the stack.
+type StackReader interface {
+	Length() int
+	Get(index int) (interface{}, error)
+	Items() []interface{}
+}
+
+// stackReader is the StackReader observers receive
+// It wraps the stack so that observers can't reach its mutating methods.
+type stackReader struct {
+	stack *Stack
+}
+
+func (r stackReader) Length() int {
+	return r.stack.Length()
+}
+
+func (r stackReader) Get(index int) (interface{}, error) {
+	return r.stack.Get(index)
+}
+
+func (r stackReader) Items() []interface{} {
+	return r.stack.Items()
+}
+
+// Observer - Receives notifications about interpreter execution
+//
+// Register an Observer with Interpreter.AddObserver to build tracing,
+// metrics, coverage or debugging tools outside the core interpreter.
+// Embed BaseObserver to implement only the callbacks you need.
+//
+// When an error propagates out of nested definitions, OnError is called
+// once for each word it passes through, innermost first.
+type Observer interface {
+	// BeforeWord is called before a word executes
+	BeforeWord(word Word, location *CodeLocation, stack StackReader)
+
+	// AfterWord is called after a word executes successfully
+	AfterWord(word Word, location *CodeLocation, stack StackReader)
+
+	// OnError is called when a word's execution fails
+	OnError(word Word, location *CodeLocation, stack StackReader, err error)
+
+	// OnDefinition is called when a definition (: or @:) is compiled
+	OnDefinition(definition *DefinitionWord, location *CodeLocation)
+
+	// OnModulePush is called when a module is pushed onto the module stack
+	OnModulePush(module *Module)
+
+	// OnModulePop is called when a module is popped off the module stack,
+	// including when a failed Run restores the module stack
+	OnModulePop(module *Module)
+
+	// OnVariableSet is called when a variable is set via Interpreter.SetVariable
+	OnVariableSet(variable *Variable, value interface{})
+}
+
+// BaseObserver provides no-op implementations of every Observer callback
+type BaseObserver struct{}
+
+func (o *BaseObserver) BeforeWord(word Word, location *CodeLocation, stack StackReader) {}
+
+func (o *BaseObserver) AfterWord(word Word, location *CodeLocation, stack StackReader) {}
+
+func (o *BaseObserver) OnError(word Word, location *CodeLocation, stack StackReader, err error) {}
+
+func (o *BaseObserver) OnDefinition(definition *DefinitionWord, location *CodeLocation) {}
+
+func (o *BaseObserver) OnModulePush(module *Module) {}
+
+func (o *BaseObserver) OnModulePop(module *Module) {}
+
+func (o *BaseObserver) OnVariableSet(variable *Variable, value interface{}) {}
+
+// ============================================================================
+// Interpreter Observer API
+// ============================================================================
+
+// AddObserver registers an observer
+// Observers are notified in the order they were added.
+func (i *Interpreter) AddObserver(observer Observer) {
+	i.observers = append(i.observers, observer)
+}
+
+// RemoveObserver unregisters an observer previously passed to AddObserver
+func (i *Interpreter) RemoveObserver(observer Observer) {
+	for j, o := range i.observers {
+		if o == observer {
+			i.observers = append(i.observers[:j:j], i.observers[j+1:]...)
+			return
+		}
+	}
+}
+
+// SetVariable sets a variable's value, notifying observers
+func (i *Interpreter) SetVariable(variable *Variable, value interface{}) {
+	variable.SetValue(value)
+	for _, o := range i.observers {
+		o.OnVariableSet(variable, value)
+	}
+}
+
+func (i *Interpreter) notifyBeforeWord(word Word, location *CodeLocation) {
+	for _, o := range i.observers {
+		o.BeforeWord(word, location, stackReader{i.stack})
+	}
+}
+
+func (i *Interpreter) notifyAfterWord(word Word, location *CodeLocation, err error) {
+	for _, o := range i.observers {
+		if err != nil {
+			o.OnError(word, location, stackReader{i.stack}, err)
+		} else {
+			o.AfterWord(word, location, stackReader{i.stack})
+		}
+	}
+}
+
+func (i *Interpreter) notifyDefinition(definition *DefinitionWord, location *CodeLocation) {
+	for _, o := range i.observers {
+		o.OnDefinition(definition, location)
+	}
+}
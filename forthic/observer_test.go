@@ -0,0 +1,150 @@
+package forthic
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// traceObserver records every notification as a string
+type traceObserver struct {
+	BaseObserver
+	events []string
+}
+
+func (o *traceObserver) BeforeWord(word Word, location *CodeLocation, stack StackReader) {
+	o.events = append(o.events, fmt.Sprintf("before %s %d", word.GetName(), stack.Length()))
+}
+
+func (o *traceObserver) AfterWord(word Word, location *CodeLocation, stack StackReader) {
+	o.events = append(o.events, fmt.Sprintf("after %s %d", word.GetName(), stack.Length()))
+}
+
+func (o *traceObserver) OnError(word Word, location *CodeLocation, stack StackReader, err error) {
+	o.events = append(o.events, fmt.Sprintf("error %s", word.GetName()))
+}
+
+func (o *traceObserver) OnDefinition(definition *DefinitionWord, location *CodeLocation) {
+	o.events = append(o.events, fmt.Sprintf("define %s at %s", definition.GetName(), location))
+}
+
+func (o *traceObserver) OnModulePush(module *Module) {
+	o.events = append(o.events, fmt.Sprintf("push {%s", module.GetName()))
+}
+
+func (o *traceObserver) OnModulePop(module *Module) {
+	o.events = append(o.events, fmt.Sprintf("pop %s}", module.GetName()))
+}
+
+func (o *traceObserver) OnVariableSet(variable *Variable, value interface{}) {
+	o.events = append(o.events, fmt.Sprintf("set %s=%v", variable.GetName(), value))
+}
+
+func TestObserver_WordEvents(t *testing.T) {
+	interp := NewInterpreter()
+	observer := &traceObserver{}
+	interp.AddObserver(observer)
+
+	assert.NoError(t, interp.Run(`: PAIR 1 2 ; PAIR`))
+	assert.Equal(t, []string{
+		"define PAIR at line 1, col 3",
+		"before PAIR 0",
+		"before 1 0",
+		"after 1 1",
+		"before 2 1",
+		"after 2 2",
+		"after PAIR 2",
+	}, observer.events)
+}
+
+func TestObserver_ErrorEvents(t *testing.T) {
+	interp := NewInterpreter()
+	observer := &traceObserver{}
+	interp.AddObserver(observer)
+
+	assert.Error(t, interp.Run(`: BROKEN ] ; BROKEN`))
+	assert.Equal(t, []string{
+		"define BROKEN at line 1, col 3",
+		"before BROKEN 0",
+		"before ] 0",
+		"error ]",
+		"error BROKEN",
+	}, observer.events)
+}
+
+func TestObserver_ModuleEvents(t *testing.T) {
+	interp := NewInterpreter()
+	observer := &traceObserver{}
+	interp.AddObserver(observer)
+
+	assert.NoError(t, interp.Run(`{mymodule }`))
+	assert.Contains(t, observer.events, "push {mymodule")
+	assert.Contains(t, observer.events, "pop mymodule}")
+}
+
+func TestObserver_FailedRunPopsModules(t *testing.T) {
+	interp := NewInterpreter()
+	observer := &traceObserver{}
+	interp.AddObserver(observer)
+
+	assert.Error(t, interp.Run(`{outer {inner NOPE`))
+	assert.Equal(t, []string{
+		"before outer 0",
+		"push {outer",
+		"after outer 0",
+		"before inner 0",
+		"push {inner",
+		"after inner 0",
+		"pop inner}",
+		"pop outer}",
+	}, observer.events)
+	assert.Equal(t, 1, len(interp.ModuleStack()))
+}
+
+// stackMutator tries to get at the stack an observer is given
+type stackMutator struct {
+	BaseObserver
+	gotStack bool
+}
+
+func (o *stackMutator) BeforeWord(word Word, location *CodeLocation, stack StackReader) {
+	if s, ok := stack.(*Stack); ok {
+		o.gotStack = true
+		s.Clear()
+	}
+}
+
+func TestObserver_StackIsReadOnly(t *testing.T) {
+	interp := NewInterpreter()
+	observer := &stackMutator{}
+	interp.AddObserver(observer)
+
+	assert.NoError(t, interp.Run(`1 2`))
+	assert.False(t, observer.gotStack)
+	assert.Equal(t, []interface{}{int64(1), int64(2)}, interp.GetStack().Items())
+}
+
+func TestObserver_VariableSet(t *testing.T) {
+	interp := NewInterpreter()
+	observer := &traceObserver{}
+	interp.AddObserver(observer)
+
+	variable := NewVariable("x", nil)
+	interp.SetVariable(variable, 42)
+	assert.Equal(t, 42, variable.GetValue())
+	assert.Equal(t, []string{"set x=42"}, observer.events)
+}
+
+func TestObserver_Remove(t *testing.T) {
+	interp := NewInterpreter()
+	first := &traceObserver{}
+	second := &traceObserver{}
+	interp.AddObserver(first)
+	interp.AddObserver(second)
+	interp.RemoveObserver(first)
+
+	assert.NoError(t, interp.Run(`1`))
+	assert.Empty(t, first.events)
+	assert.Equal(t, 2, len(second.events))
+}
@@ -0,0 +1,194 @@
+package forthic
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// ============================================================================
+// Option Schemas
+// ============================================================================
+
+// OptionType - The kind of value an option accepts
+type OptionType string
+
+const (
+	OptionAny    OptionType = "any"
+	OptionBool   OptionType = "bool"
+	OptionInt    OptionType = "int"    // int64; whole floats are accepted
+	OptionNumber OptionType = "number" // float64; integers are accepted
+	OptionString OptionType = "string"
+	OptionArray  OptionType = "array"
+	OptionRecord OptionType = "record"
+)
+
+// OptionSpec - One option a word accepts
+type OptionSpec struct {
+	Name    string // Without the leading "."
+	Type    OptionType
+	Default interface{} // Used when the option isn't given
+	Doc     string
+}
+
+// OptionSchema - The options a word accepts, declared when it is registered
+//
+// Words registered with AddModuleWordWithOptions don't handle options
+// themselves: the word pops WordOptions given with ~>, rejects unknown
+// options and values of the wrong type with an InvalidOptionError, and
+// passes the handler a WordOptions holding every option in the schema, with
+// defaults filled in. Values are converted to the option's Go type (int64,
+// float64, ...), so handlers can use them without further checks.
+//
+// The schema of a word is available to tooling via WordOptionSchema.
+type OptionSchema []OptionSpec
+
+// Find returns the spec for an option, or nil if the schema doesn't have it
+func (s OptionSchema) Find(name string) *OptionSpec {
+	for j := range s {
+		if s[j].Name == name {
+			return &s[j]
+		}
+	}
+	return nil
+}
+
+// Help returns a line per option, for help output
+//
+//	.separator string (default ", ")  Text between array items
+func (s OptionSchema) Help() string {
+	lines := make([]string, len(s))
+	for j, spec := range s {
+		line := fmt.Sprintf(".%s %s", spec.Name, spec.Type)
+		if spec.Default != nil {
+			line += fmt.Sprintf(" (default %#v)", spec.Default)
+		}
+		if spec.Doc != "" {
+			line += "  " + spec.Doc
+		}
+		lines[j] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Resolve validates options given to word against the schema
+// The result holds every option in the schema: the given value, converted
+// to the option's type, or else the default. opts may be nil.
+func (s OptionSchema) Resolve(word string, opts *WordOptions) (*WordOptions, error) {
+	resolved := &WordOptions{options: make(map[string]interface{}, len(s))}
+	for _, spec := range s {
+		if spec.Default != nil {
+			resolved.options[spec.Name] = spec.Default
+		}
+	}
+	if opts == nil {
+		return resolved, nil
+	}
+
+	// Sorted, so the same options always produce the same error
+	keys := opts.Keys()
+	sort.Strings(keys)
+	for _, key := range keys {
+		spec := s.Find(key)
+		if spec == nil {
+			return nil, NewInvalidOptionError(word, key, fmt.Sprintf("Unknown option .%s for %s", key, word))
+		}
+		value := opts.Get(key)
+		if value == nil {
+			continue
+		}
+		converted, ok := convertOption(spec.Type, value)
+		if !ok {
+			return nil, NewInvalidOptionError(word, key,
+				fmt.Sprintf("Option .%s for %s must be %s, got %s", key, word, spec.Type, describeValue(value)))
+		}
+		resolved.options[key] = converted
+	}
+	return resolved, nil
+}
+
+// convertOption converts a value to an option type's Go type
+func convertOption(optionType OptionType, value interface{}) (interface{}, bool) {
+	switch optionType {
+	case OptionBool:
+		b, ok := value.(bool)
+		return b, ok
+	case OptionInt:
+		switch v := value.(type) {
+		case int64:
+			return v, true
+		case int:
+			return int64(v), true
+		case float64:
+			if v == math.Trunc(v) && v >= math.MinInt64 && v < math.MaxInt64 {
+				return int64(v), true
+			}
+		}
+		return nil, false
+	case OptionNumber:
+		switch v := value.(type) {
+		case float64:
+			return v, true
+		case int64:
+			return float64(v), true
+		case int:
+			return float64(v), true
+		}
+		return nil, false
+	case OptionString:
+		s, ok := value.(string)
+		return s, ok
+	case OptionArray:
+		a, ok := value.([]interface{})
+		return a, ok
+	case OptionRecord:
+		r, ok := value.(map[string]interface{})
+		return r, ok
+	}
+	return value, true
+}
+
+// describeValue names the type of a Forthic value, for error messages
+func describeValue(value interface{}) string {
+	switch v := value.(type) {
+	case bool:
+		return fmt.Sprintf("bool %v", v)
+	case int, int64:
+		return fmt.Sprintf("int %v", v)
+	case float64:
+		return fmt.Sprintf("number %v", v)
+	case string:
+		return fmt.Sprintf("string %q", v)
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "record"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// popOptions pops WordOptions from the top of the stack, if present, and
+// resolves them against the schema
+func (s OptionSchema) popOptions(interp *Interpreter, word string) (*WordOptions, error) {
+	var opts *WordOptions
+	if interp.stack.Length() > 0 {
+		if given, ok := interp.StackPeek().(*WordOptions); ok {
+			interp.StackPop()
+			opts = given
+		}
+	}
+	return s.Resolve(word, opts)
+}
+
+// WordOptionSchema returns the options a word accepts, or nil if it doesn't
+// declare any
+func WordOptionSchema(word Word) OptionSchema {
+	switch w := word.(type) {
+	case *ModuleWord:
+		return w.options
+	case *ExecuteWord:
+		return WordOptionSchema(w.targetWord)
+	}
+	return nil
+}
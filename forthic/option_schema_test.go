@@ -0,0 +1,120 @@
+package forthic
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var repeatOptions = OptionSchema{
+	{Name: "times", Type: OptionInt, Default: int64(2), Doc: "Number of copies"},
+	{Name: "separator", Type: OptionString, Default: "", Doc: "Text between copies"},
+	{Name: "upper", Type: OptionBool},
+}
+
+// newRepeatInterpreter returns an interpreter with a REPEAT word that takes
+// options, and pushes the options REPEAT received
+func newRepeatInterpreter() (*Interpreter, **WordOptions) {
+	var received *WordOptions
+	module := NewModule("repeat")
+	module.AddModuleWordWithOptions("REPEAT", repeatOptions, func(interp *Interpreter, opts *WordOptions) error {
+		received = opts
+		str := interp.StackPop().(string)
+		result := str
+		for n := int64(1); n < opts.Get("times").(int64); n++ {
+			result += opts.Get("separator").(string) + str
+		}
+		interp.StackPush(result)
+		return nil
+	})
+	return NewInterpreter(module), &received
+}
+
+func withOptions(t *testing.T, interp *Interpreter, pairs ...interface{}) {
+	opts, err := NewWordOptions(pairs)
+	require.NoError(t, err)
+	interp.StackPush(opts)
+}
+
+func TestOptionSchema_Defaults(t *testing.T) {
+	interp, received := newRepeatInterpreter()
+	require.NoError(t, interp.Run(`"ab" REPEAT`))
+
+	assert.Equal(t, "abab", interp.StackPop())
+	assert.Equal(t, int64(2), (*received).Get("times"))
+	assert.False(t, (*received).Has("upper"))
+}
+
+func TestOptionSchema_ConvertsValues(t *testing.T) {
+	interp, _ := newRepeatInterpreter()
+	interp.StackPush("ab")
+	withOptions(t, interp, "times", 3.0, "separator", "-")
+	require.NoError(t, interp.Run(`REPEAT`))
+
+	assert.Equal(t, "ab-ab-ab", interp.StackPop())
+}
+
+func TestOptionSchema_UnknownOption(t *testing.T) {
+	interp, received := newRepeatInterpreter()
+	interp.StackPush("ab")
+	withOptions(t, interp, "times", int64(3), "count", int64(1))
+	err := interp.Run(`REPEAT`)
+
+	var optionErr *InvalidOptionError
+	require.True(t, errors.As(err, &optionErr), "got %v", err)
+	assert.Equal(t, "REPEAT", optionErr.Word)
+	assert.Equal(t, "count", optionErr.Option)
+	assert.Equal(t, "Unknown option .count for REPEAT", optionErr.Message)
+	assert.True(t, errors.Is(err, ErrInvalidOption))
+	assert.Nil(t, *received)
+}
+
+func TestOptionSchema_WrongType(t *testing.T) {
+	interp, _ := newRepeatInterpreter()
+	interp.StackPush("ab")
+	withOptions(t, interp, "times", 2.5)
+	err := interp.Run(`REPEAT`)
+
+	var optionErr *InvalidOptionError
+	require.True(t, errors.As(err, &optionErr), "got %v", err)
+	assert.Equal(t, "Option .times for REPEAT must be int, got number 2.5", optionErr.Message)
+
+	interp.GetStack().Clear()
+	interp.StackPush("ab")
+	withOptions(t, interp, "upper", "yes")
+	err = interp.Run(`REPEAT`)
+	require.True(t, errors.As(err, &optionErr), "got %v", err)
+	assert.Equal(t, `Option .upper for REPEAT must be bool, got string "yes"`, optionErr.Message)
+
+	// Whole numbers too large for an int64 don't fit an int option
+	interp.GetStack().Clear()
+	interp.StackPush("ab")
+	withOptions(t, interp, "times", 1e20)
+	err = interp.Run(`REPEAT`)
+	require.True(t, errors.As(err, &optionErr), "got %v", err)
+	assert.Equal(t, "Option .times for REPEAT must be int, got number 1e+20", optionErr.Message)
+}
+
+func TestOptionSchema_ExposedForTooling(t *testing.T) {
+	interp, _ := newRepeatInterpreter()
+	word := interp.GetAppModule().FindWord("REPEAT")
+	assert.Equal(t, repeatOptions, WordOptionSchema(word))
+	assert.Nil(t, WordOptionSchema(NewModuleWord("PLAIN", nil)))
+
+	assert.Equal(t, ".times int (default 2)  Number of copies\n"+
+		`.separator string (default "")  Text between copies`+"\n"+
+		".upper bool", repeatOptions.Help())
+}
+
+func TestOptionSchema_PrefixedImport(t *testing.T) {
+	module := NewModule("repeat")
+	module.AddModuleWordWithOptions("REPEAT", repeatOptions, func(interp *Interpreter, opts *WordOptions) error {
+		return nil
+	})
+	interp := NewInterpreter()
+	require.NoError(t, interp.ImportModule(module, "r"))
+
+	assert.Equal(t, repeatOptions, WordOptionSchema(interp.GetAppModule().FindWord("r.REPEAT")))
+}
@@ -0,0 +1,214 @@
+package forthic
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// WordProfile - Execution statistics for one word name while profiling
+//
+// CumulativeTime includes time spent in words called by this word; SelfTime
+// excludes it. Recursive calls count toward CumulativeTime at every level.
+type WordProfile struct {
+	Word           string
+	Count          int
+	CumulativeTime time.Duration
+	SelfTime       time.Duration
+}
+
+// ProfileTimestamp - A labeled point in time, relative to the start of profiling
+type ProfileTimestamp struct {
+	Label string
+	Time  time.Duration
+}
+
+// profile holds the data recorded by one profiling session
+type profile struct {
+	start      time.Time
+	words      map[string]*WordProfile
+	frames     []profileFrame
+	timestamps []ProfileTimestamp
+}
+
+// profileFrame tracks a word execution in progress
+type profileFrame struct {
+	stats     *WordProfile
+	start     time.Time
+	childTime time.Duration
+}
+
+func newProfile() *profile {
+	return &profile{
+		start:      time.Now(),
+		words:      make(map[string]*WordProfile),
+		frames:     make([]profileFrame, 0),
+		timestamps: make([]ProfileTimestamp, 0),
+	}
+}
+
+// enterWord records the start of a word execution
+func (p *profile) enterWord(word Word) {
+	name := word.GetName()
+	stats, ok := p.words[name]
+	if !ok {
+		stats = &WordProfile{Word: name}
+		p.words[name] = stats
+	}
+	stats.Count++
+	p.frames = append(p.frames, profileFrame{stats: stats, start: time.Now()})
+}
+
+// exitWord records the end of the innermost word execution
+func (p *profile) exitWord() {
+	if len(p.frames) == 0 {
+		return
+	}
+	frame := p.frames[len(p.frames)-1]
+	p.frames = p.frames[:len(p.frames)-1]
+
+	elapsed := time.Since(frame.start)
+	frame.stats.CumulativeTime += elapsed
+	frame.stats.SelfTime += elapsed - frame.childTime
+	if len(p.frames) > 0 {
+		p.frames[len(p.frames)-1].childTime += elapsed
+	}
+}
+
+// addTimestamp records a labeled timestamp
+func (p *profile) addTimestamp(label string) {
+	p.timestamps = append(p.timestamps, ProfileTimestamp{Label: label, Time: time.Since(p.start)})
+}
+
+// ============================================================================
+// Interpreter Profiling API
+// ============================================================================
+
+// StartProfiling starts a new profiling session, discarding earlier data
+func (i *Interpreter) StartProfiling() {
+	i.profile = newProfile()
+	i.isProfiling = true
+	i.profile.addTimestamp("START")
+}
+
+// StopProfiling ends the current profiling session
+// The recorded data remains available until profiling is started again.
+func (i *Interpreter) StopProfiling() {
+	if !i.isProfiling {
+		return
+	}
+	i.profile.addTimestamp("END")
+	i.isProfiling = false
+}
+
+// IsProfiling returns true while a profiling session is active
+func (i *Interpreter) IsProfiling() bool {
+	return i.isProfiling
+}
+
+// AddProfileTimestamp records a labeled timestamp if profiling is active
+func (i *Interpreter) AddProfileTimestamp(label string) {
+	if !i.isProfiling {
+		return
+	}
+	i.profile.addTimestamp(label)
+}
+
+// WordProfiles returns per-word statistics, most frequently executed first
+func (i *Interpreter) WordProfiles() []WordProfile {
+	if i.profile == nil {
+		return []WordProfile{}
+	}
+
+	result := make([]WordProfile, 0, len(i.profile.words))
+	for _, stats := range i.profile.words {
+		result = append(result, *stats)
+	}
+	sort.Slice(result, func(a, b int) bool {
+		if result[a].Count != result[b].Count {
+			return result[a].Count > result[b].Count
+		}
+		return result[a].Word < result[b].Word
+	})
+	return result
+}
+
+// ProfileTimestamps returns the timestamps recorded while profiling
+func (i *Interpreter) ProfileTimestamps() []ProfileTimestamp {
+	if i.profile == nil {
+		return []ProfileTimestamp{}
+	}
+	result := make([]ProfileTimestamp, len(i.profile.timestamps))
+	copy(result, i.profile.timestamps)
+	return result
+}
+
+// ProfileData returns the profiling data as a record
+//
+// The record matches the shape produced by the TypeScript runtime:
+//
+//	{"word_counts": [{"word": ..., "count": ...}, ...],
+//	 "timestamps":  [{"label": ..., "time_ms": ..., "delta": ...}, ...]}
+func (i *Interpreter) ProfileData() map[string]interface{} {
+	wordCounts := make([]interface{}, 0)
+	for _, stats := range i.WordProfiles() {
+		wordCounts = append(wordCounts, map[string]interface{}{
+			"word":  stats.Word,
+			"count": int64(stats.Count),
+		})
+	}
+
+	timestamps := make([]interface{}, 0)
+	prevTime := 0.0
+	for _, ts := range i.ProfileTimestamps() {
+		timeMs := durationToMs(ts.Time)
+		timestamps = append(timestamps, map[string]interface{}{
+			"label":   ts.Label,
+			"time_ms": timeMs,
+			"delta":   timeMs - prevTime,
+		})
+		prevTime = timeMs
+	}
+
+	return map[string]interface{}{
+		"word_counts": wordCounts,
+		"timestamps":  timestamps,
+	}
+}
+
+// ProfileReport renders the profiling data as a table, most costly words first
+// Words are ordered by self time, then cumulative time.
+func (i *Interpreter) ProfileReport() string {
+	profiles := i.WordProfiles()
+	sort.SliceStable(profiles, func(a, b int) bool {
+		if profiles[a].SelfTime != profiles[b].SelfTime {
+			return profiles[a].SelfTime > profiles[b].SelfTime
+		}
+		return profiles[a].CumulativeTime > profiles[b].CumulativeTime
+	})
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%-30s %10s %14s %14s\n", "WORD", "COUNT", "CUMULATIVE", "SELF")
+	for _, stats := range profiles {
+		fmt.Fprintf(&sb, "%-30s %10d %14s %14s\n",
+			stats.Word, stats.Count, stats.CumulativeTime, stats.SelfTime)
+	}
+
+	timestamps := i.ProfileTimestamps()
+	if len(timestamps) > 0 {
+		sb.WriteString("\n")
+		fmt.Fprintf(&sb, "%-30s %14s %14s\n", "TIMESTAMP", "TIME", "DELTA")
+		var prev time.Duration
+		for _, ts := range timestamps {
+			fmt.Fprintf(&sb, "%-30s %14s %14s\n", ts.Label, ts.Time, ts.Time-prev)
+			prev = ts.Time
+		}
+	}
+
+	return sb.String()
+}
+
+func durationToMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
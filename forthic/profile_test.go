@@ -0,0 +1,61 @@
+package forthic
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfile_CountsAndTimes(t *testing.T) {
+	interp := NewInterpreter()
+	module := NewModule("test")
+	module.AddModuleWord("SLEEP", func(interp *Interpreter) error {
+		time.Sleep(2 * time.Millisecond)
+		return nil
+	})
+	interp.ImportModule(module, "")
+	assert.NoError(t, interp.Run(`: NAP SLEEP ;`))
+
+	interp.StartProfiling()
+	assert.NoError(t, interp.Run(`NAP NAP`))
+	interp.StopProfiling()
+
+	profiles := make(map[string]WordProfile)
+	for _, p := range interp.WordProfiles() {
+		profiles[p.Word] = p
+	}
+
+	assert.Equal(t, 2, profiles["NAP"].Count)
+	assert.Equal(t, 2, profiles["SLEEP"].Count)
+	assert.GreaterOrEqual(t, profiles["SLEEP"].SelfTime, 4*time.Millisecond)
+	assert.GreaterOrEqual(t, profiles["NAP"].CumulativeTime, profiles["SLEEP"].CumulativeTime)
+	assert.Less(t, profiles["NAP"].SelfTime, profiles["SLEEP"].SelfTime)
+}
+
+func TestProfile_NotRecordedWhenOff(t *testing.T) {
+	interp := NewInterpreter()
+	assert.NoError(t, interp.Run(`1 2 3`))
+	assert.False(t, interp.IsProfiling())
+	assert.Empty(t, interp.WordProfiles())
+
+	interp.StartProfiling()
+	interp.StopProfiling()
+	assert.NoError(t, interp.Run(`1 2 3`))
+	assert.Empty(t, interp.WordProfiles())
+}
+
+func TestProfile_Report(t *testing.T) {
+	interp := NewInterpreter()
+	interp.StartProfiling()
+	assert.NoError(t, interp.Run(`: PAIR 1 2 ; PAIR`))
+	interp.AddProfileTimestamp("done")
+	interp.StopProfiling()
+
+	report := interp.ProfileReport()
+	assert.Contains(t, report, "PAIR")
+	assert.Contains(t, report, "done")
+	lines := strings.Split(report, "\n")
+	assert.True(t, strings.HasPrefix(lines[0], "WORD"))
+}
@@ -0,0 +1,45 @@
+package forthic
+
+import (
+	"sort"
+	"sync"
+)
+
+// ModuleFactory creates a new instance of a module
+//
+// Factories let USE-MODULES instantiate modules by name on demand instead of
+// requiring them to be registered with an interpreter ahead of time.
+type ModuleFactory func() *Module
+
+var (
+	moduleFactoriesMu sync.RWMutex
+	moduleFactories   = make(map[string]ModuleFactory)
+)
+
+// RegisterModuleFactory makes a module available to every interpreter by name
+// Registering a name twice replaces the earlier factory.
+func RegisterModuleFactory(name string, factory ModuleFactory) {
+	moduleFactoriesMu.Lock()
+	defer moduleFactoriesMu.Unlock()
+	moduleFactories[name] = factory
+}
+
+// LookupModuleFactory returns the globally registered factory for a module name
+func LookupModuleFactory(name string) (ModuleFactory, bool) {
+	moduleFactoriesMu.RLock()
+	defer moduleFactoriesMu.RUnlock()
+	factory, ok := moduleFactories[name]
+	return factory, ok
+}
+
+// ModuleFactoryNames returns the names of all globally registered factories, sorted
+func ModuleFactoryNames() []string {
+	moduleFactoriesMu.RLock()
+	defer moduleFactoriesMu.RUnlock()
+	names := make([]string, 0, len(moduleFactories))
+	for name := range moduleFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
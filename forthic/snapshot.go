@@ -0,0 +1,515 @@
+package forthic
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// SnapshotFormatVersion is the version of the snapshot format written by Snapshot
+const SnapshotFormatVersion = 1
+
+// snapshotSourceName is how restored definitions are named in error locations
+const snapshotSourceName = "<snapshot>"
+
+// Snapshot - The durable state of an interpreter
+//
+// A snapshot holds what is needed to rebuild an interpreter's state in a
+// fresh interpreter: the modules imported into the app module, the app
+// module's definitions (as Forthic source), module variables and, optionally,
+// the stack. Go modules themselves aren't part of a snapshot; they must be
+// available (registered, or from a module factory or loader) when restoring.
+//
+// Modules created inline with "{name ... }" are captured too: their
+// definitions are saved among the app module's, in the order they were made,
+// wrapped in "{name ... }", and their variables are saved with the module.
+//
+// Snapshots are serialized as JSON:
+//
+//	{
+//	  "version": 1,
+//	  "modules": [{"name": "reports", "prefix": "r"}],
+//	  "definitions": [": DOUBLE 2 * ;", "{util : TRIPLE 3 * ; }"],
+//	  "variables": {"": {"count": 3}},
+//	  "inline_modules": [{"path": ["util"], "variables": {"factor": 3}}],
+//	  "stack": [1, "two"]
+//	}
+//
+// Memo definitions with a TTL are saved with their options, as in
+// "[.ttl 300] ~> @: RATES ... ;", so restoring them needs ~> (from the core
+// module).
+//
+// Variables are keyed by module name, with "" for the app module. Values
+// must be NULL, booleans, numbers, strings, arrays or records; after a round
+// trip, whole numbers become int64, other numbers float64, and records
+// map[string]interface{}.
+type Snapshot struct {
+	Version       int                               `json:"version"`
+	Modules       []SnapshotModule                  `json:"modules"`
+	Definitions   []string                          `json:"definitions"`
+	Variables     map[string]map[string]interface{} `json:"variables"`
+	InlineModules []SnapshotInlineModule            `json:"inline_modules,omitempty"`
+	Stack         []interface{}                     `json:"stack,omitempty"`
+}
+
+// SnapshotInlineModule - A module created inline with "{name ... }"
+type SnapshotInlineModule struct {
+	Path      []string               `json:"path"` // Module names, from the app module down
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+// SnapshotModule - A module imported into the app module, and its prefix
+type SnapshotModule struct {
+	Name   string `json:"name"`
+	Prefix string `json:"prefix"`
+}
+
+// SnapshotOptions - What Snapshot captures beyond definitions and variables
+type SnapshotOptions struct {
+	IncludeStack bool // Capture the items on the stack
+}
+
+// ============================================================================
+// Taking Snapshots
+// ============================================================================
+
+// Snapshot captures the interpreter's durable state
+func (i *Interpreter) Snapshot(options SnapshotOptions) *Snapshot {
+	app := i.appModule
+	snapshot := &Snapshot{
+		Version:     SnapshotFormatVersion,
+		Modules:     make([]SnapshotModule, 0, len(app.modulePrefixes)),
+		Definitions: make([]string, 0, len(app.definitions)),
+		Variables:   make(map[string]map[string]interface{}),
+	}
+
+	for name, prefixes := range app.modulePrefixes {
+		if module, ok := i.registeredMods[name]; ok && module.inline {
+			continue
+		}
+		for prefix := range prefixes {
+			snapshot.Modules = append(snapshot.Modules, SnapshotModule{Name: name, Prefix: prefix})
+		}
+	}
+	sort.Slice(snapshot.Modules, func(a, b int) bool {
+		if snapshot.Modules[a].Name != snapshot.Modules[b].Name {
+			return snapshot.Modules[a].Name < snapshot.Modules[b].Name
+		}
+		return snapshot.Modules[a].Prefix < snapshot.Modules[b].Prefix
+	})
+
+	definitions := addDefinitions(nil, app, "", "")
+	definitions = snapshot.addInlineModules(definitions, app, nil)
+	sort.SliceStable(definitions, func(a, b int) bool {
+		return definitions[a].order < definitions[b].order
+	})
+	for _, definition := range definitions {
+		snapshot.Definitions = append(snapshot.Definitions, definition.source)
+	}
+
+	if values := variableValues(app); values != nil {
+		snapshot.Variables[""] = values
+	}
+	for name := range app.modulePrefixes {
+		if module, ok := i.registeredMods[name]; ok && !module.inline {
+			if values := variableValues(module); values != nil {
+				snapshot.Variables[name] = values
+			}
+		}
+	}
+
+	if options.IncludeStack {
+		snapshot.Stack = i.stack.Items()
+	}
+	return snapshot
+}
+
+// snapshotDefinition is a definition's source, ready to replay in the app module
+type snapshotDefinition struct {
+	order  uint64
+	source string
+}
+
+// addDefinitions adds a module's definitions, wrapped in prefix and suffix
+// (which enter and leave an inline module)
+func addDefinitions(definitions []snapshotDefinition, module *Module, prefix, suffix string) []snapshotDefinition {
+	for _, definition := range module.definitions {
+		if definition.source != "" {
+			source := prefix + definitionSource(definition) + suffix
+			definitions = append(definitions, snapshotDefinition{order: definition.order, source: source})
+		}
+	}
+	return definitions
+}
+
+// addInlineModules records the inline modules created in module, and adds
+// their definitions
+func (s *Snapshot) addInlineModules(definitions []snapshotDefinition, module *Module, path []string) []snapshotDefinition {
+	names := make([]string, 0, len(module.modules))
+	for name, child := range module.modules {
+		if child.inline {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		child := module.modules[name]
+		childPath := append(path[:len(path):len(path)], name)
+		s.InlineModules = append(s.InlineModules, SnapshotInlineModule{Path: childPath, Variables: variableValues(child)})
+
+		prefix, suffix := "", ""
+		for _, step := range childPath {
+			prefix += "{" + step + " "
+			suffix += " }"
+		}
+		definitions = addDefinitions(definitions, child, prefix, suffix)
+		definitions = s.addInlineModules(definitions, child, childPath)
+	}
+	return definitions
+}
+
+// variableValues returns the values of a module's variables, or nil if it has none
+func variableValues(module *Module) map[string]interface{} {
+	if len(module.variables) == 0 {
+		return nil
+	}
+	values := make(map[string]interface{}, len(module.variables))
+	for name, variable := range module.variables {
+		values[name] = variable.GetValue()
+	}
+	return values
+}
+
+// definitionSource returns the source that redefines a definition
+// A memo's options precede "@:", so they're rebuilt from the memo word.
+func definitionSource(definition *DefinitionWord) string {
+	if definition.memo == nil {
+		return definition.source
+	}
+	ttl := definition.memo.TTL()
+	switch {
+	case ttl == 0:
+		return definition.source
+	case ttl%time.Second == 0:
+		return fmt.Sprintf("[.ttl %d] ~> %s", ttl/time.Second, definition.source)
+	default:
+		return fmt.Sprintf("[.ttl \"%s\"] ~> %s", ttl, definition.source)
+	}
+}
+
+// unserializable returns a value within value that wouldn't survive a JSON
+// round trip as the same type, if there is one
+func unserializable(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case nil, bool, int, int64, float64, string:
+		return nil, false
+	case []interface{}:
+		for _, item := range v {
+			if bad, ok := unserializable(item); ok {
+				return bad, true
+			}
+		}
+		return nil, false
+	case map[string]interface{}:
+		for _, item := range v {
+			if bad, ok := unserializable(item); ok {
+				return bad, true
+			}
+		}
+		return nil, false
+	}
+	return value, true
+}
+
+// checkValues returns an error if a variable or stack value can't be
+// represented in JSON
+func (s *Snapshot) checkValues() error {
+	keys := make([]string, 0, len(s.Variables))
+	for key := range s.Variables {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := checkVariableValues(s.Variables[key]); err != nil {
+			return err
+		}
+	}
+	for _, inline := range s.InlineModules {
+		if err := checkVariableValues(inline.Variables); err != nil {
+			return err
+		}
+	}
+	for j, item := range s.Stack {
+		if bad, ok := unserializable(item); ok {
+			return NewForthicError(fmt.Sprintf("Cannot serialize snapshot: stack item %d holds a %T", j, bad))
+		}
+	}
+	return nil
+}
+
+// checkInlineImports returns an error if an inline module in module imports
+// a module, since only the app module's imports are captured
+func checkInlineImports(module *Module) error {
+	for _, child := range module.modules {
+		if !child.inline {
+			continue
+		}
+		for name, imported := range child.modules {
+			if !imported.inline {
+				return NewForthicError(fmt.Sprintf(
+					"Cannot serialize snapshot: inline module '%s' imports module '%s'", child.name, name))
+			}
+		}
+		if err := checkInlineImports(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkVariableValues returns an error if a variable's value can't be
+// represented in JSON
+func checkVariableValues(values map[string]interface{}) error {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if bad, ok := unserializable(values[name]); ok {
+			return NewForthicError(fmt.Sprintf("Cannot serialize snapshot: variable '%s' holds a %T", name, bad))
+		}
+	}
+	return nil
+}
+
+// MarshalSnapshot captures the interpreter's durable state as JSON
+// Returns an error if a variable or stack value isn't NULL, a boolean,
+// number, string, array or record, since it couldn't be restored as itself,
+// or if an inline module imports modules.
+func (i *Interpreter) MarshalSnapshot(options SnapshotOptions) ([]byte, error) {
+	if err := checkInlineImports(i.appModule); err != nil {
+		return nil, err
+	}
+	snapshot := i.Snapshot(options)
+	if err := snapshot.checkValues(); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, NewForthicError("Cannot serialize snapshot").WithCause(err)
+	}
+	return data, nil
+}
+
+// ============================================================================
+// Restoring Snapshots
+// ============================================================================
+
+// ParseSnapshot decodes a snapshot from JSON
+func ParseSnapshot(data []byte) (*Snapshot, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var snapshot Snapshot
+	if err := decoder.Decode(&snapshot); err != nil {
+		return nil, NewForthicError("Invalid snapshot").WithCause(err)
+	}
+	if snapshot.Version != SnapshotFormatVersion {
+		return nil, NewForthicError(fmt.Sprintf("Unsupported snapshot version: %d", snapshot.Version))
+	}
+
+	for _, values := range snapshot.Variables {
+		for name, value := range values {
+			values[name] = normalizeSnapshotValue(value)
+		}
+	}
+	for _, inline := range snapshot.InlineModules {
+		for name, value := range inline.Variables {
+			inline.Variables[name] = normalizeSnapshotValue(value)
+		}
+	}
+	for j, item := range snapshot.Stack {
+		snapshot.Stack[j] = normalizeSnapshotValue(item)
+	}
+	return &snapshot, nil
+}
+
+// normalizeSnapshotValue converts decoded JSON numbers to int64 or float64
+func normalizeSnapshotValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case []interface{}:
+		for j, item := range v {
+			v[j] = normalizeSnapshotValue(item)
+		}
+		return v
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalizeSnapshotValue(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// UnmarshalSnapshot restores a snapshot serialized by MarshalSnapshot
+func (i *Interpreter) UnmarshalSnapshot(data []byte) error {
+	snapshot, err := ParseSnapshot(data)
+	if err != nil {
+		return err
+	}
+	return i.RestoreSnapshot(snapshot)
+}
+
+// RestoreSnapshot rebuilds the state captured in a snapshot
+//
+// Modules are imported first; if any is unavailable, a ModuleError naming
+// it is returned before anything else is changed. Inline modules are then
+// created and variables set (so definitions can refer to them), definitions
+// replayed in the app module and stack items pushed.
+// Restoring is meant for a fresh interpreter: modules already imported with
+// the same prefix are left as they are, and other state is added to.
+//
+// Restoring is all or nothing: if it fails, the interpreter is left as it was.
+func (i *Interpreter) RestoreSnapshot(snapshot *Snapshot) (err error) {
+	if snapshot.Version != SnapshotFormatVersion {
+		return NewForthicError(fmt.Sprintf("Unsupported snapshot version: %d", snapshot.Version))
+	}
+
+	app := i.appModule
+	modules := make([]*Module, len(snapshot.Modules))
+	for j, entry := range snapshot.Modules {
+		module, err := i.FindModule(entry.Name)
+		if err != nil {
+			return NewModuleError(entry.Name,
+				fmt.Sprintf("Snapshot requires module '%s', which is not available", entry.Name)).WithCause(err)
+		}
+		modules[j] = module
+	}
+	for key := range snapshot.Variables {
+		if key != "" && !snapshotImports(snapshot, key) {
+			return NewModuleError(key, fmt.Sprintf("Snapshot has variables for module '%s', which it doesn't import", key))
+		}
+	}
+
+	// Changes are rolled back as a failed transactional Run's are; values
+	// of variables that already existed are put back separately
+	if i.transaction == nil {
+		i.beginTransaction()
+		defer func() { i.endTransaction(err) }()
+	}
+	var previous []previousValue
+	defer func() {
+		if err != nil {
+			for _, p := range previous {
+				p.variable.SetValue(p.value)
+			}
+		}
+	}()
+
+	for j, entry := range snapshot.Modules {
+		if app.modulePrefixes[entry.Name][entry.Prefix] {
+			continue
+		}
+		app.ImportModule(entry.Prefix, modules[j], i)
+	}
+
+	for key, values := range snapshot.Variables {
+		module := app
+		if key != "" {
+			module = i.registeredMods[key]
+		}
+		previous = restoreVariables(module, values, previous)
+	}
+	for _, inline := range snapshot.InlineModules {
+		module, err := i.inlineModule(inline.Path)
+		if err != nil {
+			return err
+		}
+		previous = restoreVariables(module, inline.Variables, previous)
+	}
+
+	if err := i.restoreDefinitions(snapshot.Definitions); err != nil {
+		return err
+	}
+
+	for _, item := range snapshot.Stack {
+		i.StackPush(item)
+	}
+	return nil
+}
+
+// previousValue is the value a variable had before a snapshot was restored
+type previousValue struct {
+	variable *Variable
+	value    interface{}
+}
+
+// restoreVariables sets a module's variables, adding any it doesn't have,
+// and returns previous with the values of those it had added
+func restoreVariables(module *Module, values map[string]interface{}, previous []previousValue) []previousValue {
+	for name, value := range values {
+		if variable := module.GetVariable(name); variable != nil {
+			previous = append(previous, previousValue{variable: variable, value: variable.GetValue()})
+		} else {
+			module.AddVariable(name, nil)
+		}
+		module.GetVariable(name).SetValue(value)
+	}
+	return previous
+}
+
+// inlineModule returns the inline module at path, creating it (and the
+// modules it's nested in) as "{name" does
+func (i *Interpreter) inlineModule(path []string) (*Module, error) {
+	depth := len(i.moduleStack)
+	i.ModuleStackPush(i.appModule)
+	defer func() {
+		for len(i.moduleStack) > depth {
+			i.ModuleStackPop()
+		}
+	}()
+	for _, name := range path {
+		if err := NewStartModuleWord(name).Execute(i); err != nil {
+			return nil, err
+		}
+	}
+	return i.CurModule(), nil
+}
+
+// restoreDefinitions replays definitions with the app module as the current module
+func (i *Interpreter) restoreDefinitions(definitions []string) error {
+	depth := len(i.moduleStack)
+	i.ModuleStackPush(i.appModule)
+	defer func() {
+		for len(i.moduleStack) > depth {
+			i.ModuleStackPop()
+		}
+	}()
+	for _, source := range definitions {
+		if err := i.RunSource(snapshotSourceName, source); err != nil {
+			return NewForthicError("Cannot restore definition").WithForthic(source).WithCause(err)
+		}
+	}
+	return nil
+}
+
+// snapshotImports reports whether the snapshot imports the named module
+func snapshotImports(snapshot *Snapshot, name string) bool {
+	for _, entry := range snapshot.Modules {
+		if entry.Name == name {
+			return true
+		}
+	}
+	return false
+}
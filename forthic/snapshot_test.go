@@ -0,0 +1,230 @@
+package forthic
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCalcModule returns a Go module with words for doubling integers
+func newCalcModule() *Module {
+	module := NewModule("calc")
+	module.AddModuleWord("DUP", func(interp *Interpreter) error {
+		a := interp.StackPeek()
+		interp.StackPush(a)
+		return nil
+	})
+	module.AddModuleWord("ADD", func(interp *Interpreter) error {
+		b := interp.StackPop().(int64)
+		a := interp.StackPop().(int64)
+		interp.StackPush(a + b)
+		return nil
+	})
+	module.AddExportable([]string{"DUP", "ADD"})
+	return module
+}
+
+// newCalcInterpreter returns an interpreter that can find the calc module
+func newCalcInterpreter() *Interpreter {
+	interp := NewInterpreter()
+	interp.RegisterModuleFactory("calc", newCalcModule)
+	return interp
+}
+
+func TestSnapshot_RoundTrip(t *testing.T) {
+	interp := newCalcInterpreter()
+	require.NoError(t, interp.UseModules([]interface{}{[]interface{}{"calc", "c"}}))
+	require.NoError(t, interp.Run(`
+		: DOUBLE   c.DUP c.ADD ;
+		: QUAD     DOUBLE DOUBLE ;
+		@: ANSWER  21 DOUBLE ;
+		7 "seven"
+	`))
+	interp.GetAppModule().AddVariable("limit", int64(10))
+	interp.GetAppModule().AddVariable("settings", map[string]interface{}{"ratio": 0.5, "tags": []interface{}{"a"}})
+
+	data, err := interp.MarshalSnapshot(SnapshotOptions{IncludeStack: true})
+	require.NoError(t, err)
+
+	restored := newCalcInterpreter()
+	require.NoError(t, restored.UnmarshalSnapshot(data))
+
+	assert.Equal(t, []interface{}{int64(7), "seven"}, restored.GetStack().Items())
+	require.NoError(t, restored.Run(`3 QUAD ANSWER`))
+	assert.Equal(t, []interface{}{int64(7), "seven", int64(12), int64(42)}, restored.GetStack().Items())
+
+	app := restored.GetAppModule()
+	assert.Equal(t, int64(10), app.GetVariable("limit").GetValue())
+	assert.Equal(t, map[string]interface{}{"ratio": 0.5, "tags": []interface{}{"a"}}, app.GetVariable("settings").GetValue())
+}
+
+func TestSnapshot_Contents(t *testing.T) {
+	interp := newCalcInterpreter()
+	require.NoError(t, interp.UseModules([]interface{}{"calc", []interface{}{"calc", "c"}}))
+	require.NoError(t, interp.Run(": A 1 ;\n: B  A\n  A ;\n: A 2 ;\n{scratch : HIDDEN 3 ; }\n5"))
+
+	snapshot := interp.Snapshot(SnapshotOptions{})
+	assert.Equal(t, SnapshotFormatVersion, snapshot.Version)
+	assert.Equal(t, []SnapshotModule{{Name: "calc", Prefix: ""}, {Name: "calc", Prefix: "c"}}, snapshot.Modules)
+	assert.Equal(t, []string{": A 1 ;", ": B  A\n  A ;", ": A 2 ;", "{scratch : HIDDEN 3 ; }"}, snapshot.Definitions)
+	assert.Equal(t, []SnapshotInlineModule{{Path: []string{"scratch"}}}, snapshot.InlineModules)
+	assert.Nil(t, snapshot.Stack)
+
+	data, err := interp.MarshalSnapshot(SnapshotOptions{})
+	require.NoError(t, err)
+	var raw map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &raw))
+	assert.NotContains(t, raw, "stack")
+}
+
+func TestSnapshot_RedefinitionsReplayInOrder(t *testing.T) {
+	interp := NewInterpreter()
+	require.NoError(t, interp.Run(`: A 1 ; : B A ; : A 2 ;`))
+
+	restored := NewInterpreter()
+	require.NoError(t, restored.RestoreSnapshot(interp.Snapshot(SnapshotOptions{})))
+	require.NoError(t, restored.Run(`A B`))
+	assert.Equal(t, []interface{}{int64(2), int64(1)}, restored.GetStack().Items())
+}
+
+func TestSnapshot_MissingModule(t *testing.T) {
+	interp := newCalcInterpreter()
+	require.NoError(t, interp.UseModules([]interface{}{"calc"}))
+	require.NoError(t, interp.Run(`: DOUBLE DUP ADD ;`))
+	data, err := interp.MarshalSnapshot(SnapshotOptions{})
+	require.NoError(t, err)
+
+	restored := NewInterpreter()
+	err = restored.UnmarshalSnapshot(data)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Snapshot requires module 'calc', which is not available")
+
+	var moduleErr *ModuleError
+	require.True(t, errors.As(err, &moduleErr))
+	assert.Equal(t, "calc", moduleErr.Module)
+	assert.True(t, errors.Is(err, ErrUnknownModule))
+
+	// Nothing was restored
+	assert.Nil(t, restored.GetAppModule().FindWord("DOUBLE"))
+}
+
+func TestSnapshot_UnserializableValue(t *testing.T) {
+	interp := NewInterpreter()
+	interp.StackPush(func() {})
+
+	_, err := interp.MarshalSnapshot(SnapshotOptions{IncludeStack: true})
+	assert.ErrorContains(t, err, "Cannot serialize snapshot")
+
+	_, err = interp.MarshalSnapshot(SnapshotOptions{})
+	assert.NoError(t, err)
+
+	// Values JSON would bring back as another type are rejected too
+	interp.GetAppModule().AddVariable("when", []interface{}{time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)})
+	_, err = interp.MarshalSnapshot(SnapshotOptions{})
+	assert.ErrorContains(t, err, "Cannot serialize snapshot: variable 'when' holds a time.Time")
+}
+
+func TestSnapshot_MemoOptions(t *testing.T) {
+	interp := NewInterpreter()
+	require.NoError(t, interp.Run(`@: FOREVER 1 ; @: HOURLY 2 ; @: BRIEF 3 ;`))
+	app := interp.GetAppModule()
+	app.FindWord("HOURLY").(*ModuleMemoWord).SetTTL(time.Hour)
+	app.FindWord("BRIEF").(*ModuleMemoWord).SetTTL(1500 * time.Millisecond)
+
+	assert.Equal(t, []string{
+		"@: FOREVER 1 ;",
+		"[.ttl 3600] ~> @: HOURLY 2 ;",
+		`[.ttl "1.5s"] ~> @: BRIEF 3 ;`,
+	}, interp.Snapshot(SnapshotOptions{}).Definitions)
+}
+
+func TestSnapshot_InlineModules(t *testing.T) {
+	interp := newCalcInterpreter()
+	require.NoError(t, interp.UseModules([]interface{}{"calc"}))
+	require.NoError(t, interp.Run(`
+		: DBL DUP ADD ;
+		{util : HI 21 ; {deep : LO 1 ; } }
+		: USEHI {util HI } DBL ;
+		{util : HI2 USEHI {deep LO } ADD ; }
+	`))
+	interp.GetAppModule().FindModule("util").AddVariable("factor", int64(3))
+
+	snapshot := interp.Snapshot(SnapshotOptions{})
+	assert.Equal(t, []string{
+		": DBL DUP ADD ;",
+		"{util : HI 21 ; }",
+		"{util {deep : LO 1 ; } }",
+		": USEHI {util HI } DBL ;",
+		"{util : HI2 USEHI {deep LO } ADD ; }",
+	}, snapshot.Definitions)
+
+	data, err := interp.MarshalSnapshot(SnapshotOptions{})
+	require.NoError(t, err)
+	restored := newCalcInterpreter()
+	require.NoError(t, restored.UnmarshalSnapshot(data))
+	require.NoError(t, restored.Run(`USEHI {util HI2 }`))
+	assert.Equal(t, []interface{}{int64(42), int64(43)}, restored.GetStack().Items())
+	assert.Equal(t, int64(3), restored.GetAppModule().FindModule("util").GetVariable("factor").GetValue())
+}
+
+func TestSnapshot_FailedRestoreChangesNothing(t *testing.T) {
+	interp := newCalcInterpreter()
+	interp.GetAppModule().AddVariable("limit", int64(1))
+	require.NoError(t, interp.Run(`1`))
+	snapshot := &Snapshot{
+		Version:       SnapshotFormatVersion,
+		Modules:       []SnapshotModule{{Name: "calc", Prefix: ""}},
+		Definitions:   []string{": DBL DUP ADD ;", "{util : HI 1 ; }", ": BROKEN NOPE ;"},
+		Variables:     map[string]map[string]interface{}{"": {"limit": int64(10), "added": int64(2)}},
+		InlineModules: []SnapshotInlineModule{{Path: []string{"util"}}},
+		Stack:         []interface{}{int64(2)},
+	}
+
+	err := interp.RestoreSnapshot(snapshot)
+	assert.True(t, errors.Is(err, ErrUnknownWord), "got %v", err)
+
+	app := interp.GetAppModule()
+	assert.Nil(t, app.FindWord("DBL"))
+	assert.Nil(t, app.FindWord("ADD"))
+	assert.Nil(t, app.FindModule("util"))
+	assert.Nil(t, app.GetVariable("added"))
+	assert.Equal(t, int64(1), app.GetVariable("limit").GetValue())
+	assert.Equal(t, []interface{}{int64(1)}, interp.GetStack().Items())
+	_, err = interp.FindModule("util")
+	assert.True(t, errors.Is(err, ErrUnknownModule))
+}
+
+func TestSnapshot_InlineModuleImports(t *testing.T) {
+	interp := newCalcInterpreter()
+	require.NoError(t, interp.Run(`{util }`))
+	util := interp.GetAppModule().FindModule("util")
+	util.ImportModule("c", newCalcModule(), interp)
+
+	_, err := interp.MarshalSnapshot(SnapshotOptions{})
+	assert.ErrorContains(t, err, "Cannot serialize snapshot: inline module 'util' imports module 'calc'")
+}
+
+func TestSnapshot_InvalidData(t *testing.T) {
+	_, err := ParseSnapshot([]byte(`{"version": 2}`))
+	assert.ErrorContains(t, err, "Unsupported snapshot version: 2")
+
+	_, err = ParseSnapshot([]byte(`not json`))
+	assert.ErrorContains(t, err, "Invalid snapshot")
+}
+
+func TestSnapshot_FailedDefinition(t *testing.T) {
+	snapshot := &Snapshot{Version: SnapshotFormatVersion, Definitions: []string{": BROKEN NOPE ;"}}
+
+	err := NewInterpreter().RestoreSnapshot(snapshot)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Cannot restore definition")
+	assert.True(t, errors.Is(err, ErrUnknownWord))
+
+	var unknown *UnknownWordError
+	require.True(t, errors.As(err, &unknown))
+	assert.Equal(t, "<snapshot>", unknown.Location.File)
+}
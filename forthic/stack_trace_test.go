@@ -0,0 +1,96 @@
+package forthic
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func traceWords(trace []StackFrame) []string {
+	names := make([]string, len(trace))
+	for j, frame := range trace {
+		names[j] = frame.Word
+	}
+	return names
+}
+
+func TestStackTrace_NestedDefinitions(t *testing.T) {
+	interp := NewInterpreter()
+	err := interp.Run(": INNER 1 ] ;\n: OUTER INNER ;\nOUTER")
+	assert.Error(t, err)
+
+	trace := GetStackTrace(err)
+	assert.Equal(t, []string{"]", "INNER", "OUTER"}, traceWords(trace))
+	assert.Equal(t, 1, trace[0].Location.Line)
+	assert.Equal(t, 11, trace[0].Location.Column)
+	assert.Equal(t, 2, trace[1].Location.Line)
+	assert.Equal(t, 3, trace[2].Location.Line)
+
+	var underflow *StackUnderflowError
+	assert.True(t, errors.As(err, &underflow))
+	assert.Equal(t, trace, underflow.StackTrace())
+}
+
+func TestStackTrace_Rendering(t *testing.T) {
+	interp := NewInterpreter()
+	err := interp.Run(": INNER ] ;\nINNER")
+
+	message := err.Error()
+	assert.True(t, strings.HasPrefix(message, "Stack underflow"))
+	assert.Contains(t, message, "forthic stack trace:\n]\n\tline 1, col 9\nINNER\n\tline 2, col 1")
+}
+
+func TestStackTrace_RenderingElidesDeepTraces(t *testing.T) {
+	module := NewModule("interpret")
+	module.AddModuleWord("INTERPRET", func(interp *Interpreter) error {
+		return interp.Run(interp.StackPop().(string))
+	})
+	interp := NewInterpreter(module)
+	err := interp.Run(`: R "R" INTERPRET ; R`)
+	assert.True(t, errors.Is(err, ErrResourceLimit), "got %v", err)
+
+	trace := GetStackTrace(err)
+	assert.Greater(t, len(trace), 2*traceEndFrames)
+
+	message := err.Error()
+	elided := len(trace) - 2*traceEndFrames
+	assert.Contains(t, message, fmt.Sprintf("\n... %d frames elided ...\n", elided))
+	assert.Equal(t, 2*traceEndFrames, strings.Count(message, "\n\t"))
+	assert.Less(t, len(message), 10000)
+}
+
+func TestStackTrace_WrapsPlainErrors(t *testing.T) {
+	interp := NewInterpreter()
+	plain := errors.New("boom")
+	module := NewModule("test")
+	module.AddModuleWord("FAIL", func(interp *Interpreter) error {
+		return plain
+	})
+	interp.ImportModule(module, "")
+
+	err := interp.Run(`: WRAPPER FAIL ; WRAPPER`)
+	assert.True(t, errors.Is(err, plain))
+	assert.Equal(t, []string{"FAIL", "WRAPPER"}, traceWords(GetStackTrace(err)))
+
+	var execErr *WordExecutionError
+	assert.True(t, errors.As(err, &execErr))
+	assert.Equal(t, "FAIL", execErr.Word)
+}
+
+func TestStackTrace_CodeFrames(t *testing.T) {
+	frame := StackFrame{
+		Word:     "<code>",
+		Location: &CodeLocation{File: "report.forthic", Line: 3, Column: 7},
+		Code:     "2 *",
+		Item:     4,
+	}
+	assert.Equal(t, "report.forthic", frame.File())
+	assert.Equal(t, "<code> \"2 *\" (item 4)\n\treport.forthic:3:7", frame.String())
+}
+
+func TestStackTrace_NoTraceForPlainError(t *testing.T) {
+	assert.Nil(t, GetStackTrace(errors.New("plain")))
+}
@@ -71,6 +71,7 @@ type Tokenizer struct {
 	tokenString       strings.Builder
 	stringDelta       *stringDelta
 	streaming         bool
+	definitionStart   int // Input position of the ":" or "@:" of the last definition
 }
 
 func NewTokenizer(inputString string, referenceLocation *CodeLocation, streaming bool) *Tokenizer {
@@ -180,6 +181,7 @@ func (t *Tokenizer) advancePosition(numChars int) {
 func (t *Tokenizer) getTokenLocation() *CodeLocation {
 	return &CodeLocation{
 		Source:   t.referenceLocation.Source,
+		File:     t.referenceLocation.File,
 		Line:     t.tokenLine,
 		Column:   t.tokenColumn,
 		StartPos: t.tokenStartPos,
@@ -187,6 +189,15 @@ func (t *Tokenizer) getTokenLocation() *CodeLocation {
 	}
 }
 
+// definitionSource returns the input from the start of the last definition
+// up to the current position (just past its ";")
+func (t *Tokenizer) definitionSource() string {
+	if t.definitionStart > t.inputPos {
+		return ""
+	}
+	return t.inputString[t.definitionStart:t.inputPos]
+}
+
 // ============================================================================
 // Public API
 // ============================================================================
@@ -211,8 +222,10 @@ func (t *Tokenizer) transitionFromSTART() (*Token, error) {
 		} else if ch == '#' {
 			return t.transitionFromCOMMENT()
 		} else if ch == ':' {
+			t.definitionStart = t.inputPos - 1
 			return t.transitionFromSTART_DEFINITION()
 		} else if t.isStartMemo(t.inputPos - 1) {
+			t.definitionStart = t.inputPos - 1
 			t.advancePosition(1) // Skip over ":" in "@:"
 			return t.transitionFromSTART_MEMO()
 		} else if ch == ';' {
@@ -268,7 +281,7 @@ func (t *Tokenizer) transitionFromSTART_DEFINITION() (*Token, error) {
 			continue
 		} else if t.isQuote(ch) {
 			return nil, NewForthicError("Definition names can't have quotes in them").
-				WithLocation(&CodeLocation{Line: t.tokenLine, Column: t.tokenColumn})
+				WithLocation(&CodeLocation{File: t.referenceLocation.File, Line: t.tokenLine, Column: t.tokenColumn})
 		} else {
 			t.advancePosition(-1)
 			return t.transitionFromGATHER_DEFINITION_NAME()
@@ -276,7 +289,7 @@ func (t *Tokenizer) transitionFromSTART_DEFINITION() (*Token, error) {
 	}
 
 	return nil, NewForthicError("Got EOS in START_DEFINITION").
-		WithLocation(&CodeLocation{Line: t.tokenLine, Column: t.tokenColumn})
+		WithLocation(&CodeLocation{File: t.referenceLocation.File, Line: t.tokenLine, Column: t.tokenColumn})
 }
 
 func (t *Tokenizer) transitionFromSTART_MEMO() (*Token, error) {
@@ -288,7 +301,7 @@ func (t *Tokenizer) transitionFromSTART_MEMO() (*Token, error) {
 			continue
 		} else if t.isQuote(ch) {
 			return nil, NewForthicError("Memo names can't have quotes in them").
-				WithLocation(&CodeLocation{Line: t.tokenLine, Column: t.tokenColumn})
+				WithLocation(&CodeLocation{File: t.referenceLocation.File, Line: t.tokenLine, Column: t.tokenColumn})
 		} else {
 			t.advancePosition(-1)
 			return t.transitionFromGATHER_MEMO_NAME()
@@ -296,7 +309,7 @@ func (t *Tokenizer) transitionFromSTART_MEMO() (*Token, error) {
 	}
 
 	return nil, NewForthicError("Got EOS in START_MEMO").
-		WithLocation(&CodeLocation{Line: t.tokenLine, Column: t.tokenColumn})
+		WithLocation(&CodeLocation{File: t.referenceLocation.File, Line: t.tokenLine, Column: t.tokenColumn})
 }
 
 func (t *Tokenizer) gatherDefinitionName() error {
@@ -309,11 +322,11 @@ func (t *Tokenizer) gatherDefinitionName() error {
 		}
 		if t.isQuote(ch) {
 			return NewForthicError("Definition names can't have quotes in them").
-				WithLocation(&CodeLocation{Line: t.tokenLine, Column: t.tokenColumn})
+				WithLocation(&CodeLocation{File: t.referenceLocation.File, Line: t.tokenLine, Column: t.tokenColumn})
 		}
 		if strings.ContainsRune("[]{}", ch) {
 			return NewForthicError("Definition names can't have '" + string(ch) + "' in them").
-				WithLocation(&CodeLocation{Line: t.tokenLine, Column: t.tokenColumn})
+				WithLocation(&CodeLocation{File: t.referenceLocation.File, Line: t.tokenLine, Column: t.tokenColumn})
 		}
 		t.tokenString.WriteRune(ch)
 	}
@@ -388,7 +401,7 @@ func (t *Tokenizer) transitionFromGATHER_TRIPLE_QUOTE_STRING(delim rune) (*Token
 		return nil, nil
 	}
 	return nil, NewForthicError("Unterminated string").
-		WithLocation(&CodeLocation{Line: t.tokenLine, Column: t.tokenColumn})
+		WithLocation(&CodeLocation{File: t.referenceLocation.File, Line: t.tokenLine, Column: t.tokenColumn})
 }
 
 func (t *Tokenizer) transitionFromGATHER_STRING(delim rune) (*Token, error) {
@@ -414,7 +427,7 @@ func (t *Tokenizer) transitionFromGATHER_STRING(delim rune) (*Token, error) {
 		return nil, nil
 	}
 	return nil, NewForthicError("Unterminated string").
-		WithLocation(&CodeLocation{Line: t.tokenLine, Column: t.tokenColumn})
+		WithLocation(&CodeLocation{File: t.referenceLocation.File, Line: t.tokenLine, Column: t.tokenColumn})
 }
 
 func (t *Tokenizer) transitionFromGATHER_WORD() (*Token, error) {
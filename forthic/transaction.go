@@ -0,0 +1,138 @@
+package forthic
+
+// ============================================================================
+// Transactional Runs
+// ============================================================================
+
+// SetTransactional turns transactional mode on or off
+//
+// Run always restores the module stack and compile state when it fails. In
+// transactional mode, a failed Run also rolls back everything else it
+// changed: the stack is restored to its previous contents, and words,
+// variables, imports and registered modules added during the Run are removed.
+// This makes it safe to retry code, or keep going in a REPL, after an error.
+//
+// Only the outermost Run is a transaction; code it runs (as with INTERPRET)
+// is part of the same transaction. Values assigned to variables that existed
+// before the Run, and changes made inside values, are not rolled back.
+func (i *Interpreter) SetTransactional(enabled bool) {
+	i.transactional = enabled
+}
+
+// IsTransactional returns true if transactional mode is on
+func (i *Interpreter) IsTransactional() bool {
+	return i.transactional
+}
+
+// transaction records the state to roll back to if a transactional Run fails
+type transaction struct {
+	stack          []interface{}
+	registeredMods map[string]*Module
+	marks          map[*Module]*moduleMark
+}
+
+// moduleMark is the state of a module before its first change in a transaction
+type moduleMark struct {
+	numWords       int
+	numExportable  int
+	numDefinitions int
+	variables      map[string]*Variable
+	modules        map[string]*Module
+	modulePrefixes map[string]map[string]bool
+}
+
+// beginTransaction starts recording changes made by a Run
+func (i *Interpreter) beginTransaction() {
+	registeredMods := make(map[string]*Module, len(i.registeredMods))
+	for name, module := range i.registeredMods {
+		registeredMods[name] = module
+	}
+	i.transaction = &transaction{
+		stack:          i.stack.Items(),
+		registeredMods: registeredMods,
+		marks:          make(map[*Module]*moduleMark),
+	}
+}
+
+// endTransaction stops recording changes, rolling them back if err is set
+func (i *Interpreter) endTransaction(err error) {
+	tx := i.transaction
+	i.transaction = nil
+	if err == nil {
+		return
+	}
+
+	i.stack.items = tx.stack
+	i.registeredMods = tx.registeredMods
+	for module, mark := range tx.marks {
+		module.rollback(mark)
+	}
+}
+
+// record saves a module's state, the first time it changes in the transaction
+func (tx *transaction) record(m *Module) {
+	if _, ok := tx.marks[m]; ok {
+		return
+	}
+
+	mark := &moduleMark{
+		numWords:       len(m.words),
+		numExportable:  len(m.exportable),
+		numDefinitions: len(m.definitions),
+		variables:      make(map[string]*Variable, len(m.variables)),
+		modules:        make(map[string]*Module, len(m.modules)),
+		modulePrefixes: make(map[string]map[string]bool, len(m.modulePrefixes)),
+	}
+	for name, variable := range m.variables {
+		mark.variables[name] = variable
+	}
+	for name, module := range m.modules {
+		mark.modules[name] = module
+	}
+	for name, prefixes := range m.modulePrefixes {
+		copied := make(map[string]bool, len(prefixes))
+		for prefix := range prefixes {
+			copied[prefix] = true
+		}
+		mark.modulePrefixes[name] = copied
+	}
+	tx.marks[m] = mark
+}
+
+// recordChange is called before a module changes
+// It lets an active transaction save the module's state, and gives a module
+// shared with a fork its own dictionary.
+func (m *Module) recordChange() {
+	m.unshare()
+	if m.interp != nil && m.interp.transaction != nil {
+		m.interp.transaction.record(m)
+	}
+}
+
+// rollback restores a module to the state saved in mark
+func (m *Module) rollback(mark *moduleMark) {
+	// Full slice expressions make later appends reallocate rather than
+	// overwrite the rolled-back entries
+	m.words = m.words[:mark.numWords:mark.numWords]
+	m.exportable = m.exportable[:mark.numExportable:mark.numExportable]
+	m.definitions = m.definitions[:mark.numDefinitions:mark.numDefinitions]
+
+	m.wordIndex = make(map[string]Word, len(m.words))
+	for _, word := range m.words {
+		m.wordIndex[word.GetName()] = word
+	}
+
+	m.variables = mark.variables
+	for name := range m.variableWords {
+		if m.variables[name] == nil {
+			delete(m.variableWords, name)
+		}
+	}
+
+	m.modules = mark.modules
+	m.modulePrefixes = mark.modulePrefixes
+
+	// The version only ever increases, so caches never mistake the rolled
+	// back module for a state they saw during the transaction
+	m.touch()
+}
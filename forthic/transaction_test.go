@@ -0,0 +1,100 @@
+package forthic
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTransactionalInterpreter returns a transactional interpreter with words
+// that fail and that add a variable from Go
+func newTransactionalInterpreter() *Interpreter {
+	module := NewModule("tx")
+	module.AddModuleWord("FAIL", func(interp *Interpreter) error {
+		return errors.New("failed")
+	})
+	module.AddModuleWord("ADD-VAR", func(interp *Interpreter) error {
+		name := interp.StackPop().(string)
+		interp.CurModule().AddVariable(name, int64(0))
+		return nil
+	})
+	interp := NewInterpreter(module)
+	interp.SetTransactional(true)
+	return interp
+}
+
+func TestTransaction_RestoresStack(t *testing.T) {
+	interp := newTransactionalInterpreter()
+	require.NoError(t, interp.Run(`1 2 3`))
+
+	assert.Error(t, interp.Run(`4 5 FAIL`))
+	assert.Equal(t, []interface{}{int64(1), int64(2), int64(3)}, interp.GetStack().Items())
+}
+
+func TestTransaction_RemovesDefinitions(t *testing.T) {
+	interp := newTransactionalInterpreter()
+	require.NoError(t, interp.Run(`: B 2 ;`))
+
+	assert.Error(t, interp.Run(`: A 1 ; : B 3 ; A B FAIL`))
+	assert.Nil(t, interp.GetAppModule().FindWord("A"))
+	assert.Len(t, interp.GetAppModule().Definitions(), 1)
+
+	err := interp.Run(`A`)
+	assert.True(t, errors.Is(err, ErrUnknownWord))
+
+	require.NoError(t, interp.Run(`B`))
+	assert.Equal(t, []interface{}{int64(2)}, interp.GetStack().Items())
+}
+
+func TestTransaction_RemovesVariables(t *testing.T) {
+	interp := newTransactionalInterpreter()
+	require.NoError(t, interp.Run(`"kept" ADD-VAR`))
+
+	assert.Error(t, interp.Run(`"dropped" ADD-VAR FAIL`))
+	app := interp.GetAppModule()
+	assert.NotNil(t, app.GetVariable("kept"))
+	assert.Nil(t, app.GetVariable("dropped"))
+	assert.Nil(t, app.FindVariable("dropped"))
+}
+
+func TestTransaction_RemovesModules(t *testing.T) {
+	interp := newTransactionalInterpreter()
+
+	assert.Error(t, interp.Run(`{scratch : X 1 ; } FAIL`))
+	assert.Nil(t, interp.GetAppModule().FindModule("scratch"))
+	_, err := interp.FindModule("scratch")
+	assert.True(t, errors.Is(err, ErrUnknownModule))
+
+	err = interp.Run(`{scratch X }`)
+	assert.True(t, errors.Is(err, ErrUnknownWord))
+}
+
+func TestTransaction_RestoresCompileState(t *testing.T) {
+	interp := newTransactionalInterpreter()
+
+	assert.Error(t, interp.Run(`{scratch : HALF 1 FAIL`))
+	assert.Len(t, interp.ModuleStack(), 1)
+	require.NoError(t, interp.Run(`7`))
+	assert.Equal(t, []interface{}{int64(7)}, interp.GetStack().Items())
+}
+
+func TestTransaction_KeepsChangesOnSuccess(t *testing.T) {
+	interp := newTransactionalInterpreter()
+
+	require.NoError(t, interp.Run(`: A 1 ; "v" ADD-VAR A`))
+	assert.NotNil(t, interp.GetAppModule().FindWord("A"))
+	assert.NotNil(t, interp.GetAppModule().GetVariable("v"))
+	assert.Equal(t, []interface{}{int64(1)}, interp.GetStack().Items())
+}
+
+func TestTransaction_Disabled(t *testing.T) {
+	interp := newTransactionalInterpreter()
+	interp.SetTransactional(false)
+	assert.False(t, interp.IsTransactional())
+
+	assert.Error(t, interp.Run(`: A 1 ; 4 FAIL`))
+	assert.NotNil(t, interp.GetAppModule().FindWord("A"))
+	assert.Equal(t, []interface{}{int64(4)}, interp.GetStack().Items())
+}
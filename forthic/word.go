@@ -1,5 +1,11 @@
 package forthic
 
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
 // Word - Base class for all executable words in Forthic
 //
 // A word is the fundamental unit of execution in Forthic. When interpreted,
@@ -11,8 +17,9 @@ type Word interface {
 	GetString() string
 	GetLocation() *CodeLocation
 	SetLocation(location *CodeLocation)
-	AddErrorHandler(handler WordErrorHandler)
-	RemoveErrorHandler(handler WordErrorHandler)
+	AddErrorHandler(handler WordErrorHandler) ErrorHandlerHandle
+	AddErrorHandlerWithOptions(handler WordErrorHandler, options ErrorHandlerOptions) ErrorHandlerHandle
+	RemoveErrorHandler(handle ErrorHandlerHandle) bool
 	ClearErrorHandlers()
 	GetErrorHandlers() []WordErrorHandler
 	GetRuntimeInfo() *RuntimeInfo
@@ -22,6 +29,30 @@ type Word interface {
 // Returns nil if error was handled, or returns error if it should propagate
 type WordErrorHandler func(error, Word, *Interpreter) error
 
+// ErrorHandlerHandle identifies an error handler added to a word
+// Pass it to RemoveErrorHandler to detach the handler. Handles are unique
+// across all words.
+type ErrorHandlerHandle uint64
+
+// ErrorHandlerOptions - How an error handler is attached to a word
+//
+// Handlers with a higher Priority are tried first; handlers with equal
+// priority are tried in the order they were added. If Interp is set, the
+// handler only applies when the word executes in that interpreter.
+type ErrorHandlerOptions struct {
+	Priority int
+	Interp   *Interpreter
+}
+
+// lastErrorHandlerHandle is the most recently issued ErrorHandlerHandle
+var lastErrorHandlerHandle uint64
+
+type errorHandlerEntry struct {
+	handle  ErrorHandlerHandle
+	handler WordErrorHandler
+	options ErrorHandlerOptions
+}
+
 // RuntimeInfo - Metadata about where and how a word can execute
 //
 // Used by the ExecutionPlanner to batch remote word execution efficiently.
@@ -39,7 +70,8 @@ type BaseWord struct {
 	name          string
 	str           string
 	location      *CodeLocation
-	errorHandlers []WordErrorHandler
+	handlersMu    sync.RWMutex
+	errorHandlers []errorHandlerEntry
 }
 
 // NewBaseWord creates a new BaseWord
@@ -48,7 +80,7 @@ func NewBaseWord(name string) *BaseWord {
 		name:          name,
 		str:           name,
 		location:      nil,
-		errorHandlers: make([]WordErrorHandler, 0),
+		errorHandlers: make([]errorHandlerEntry, 0),
 	}
 }
 
@@ -72,38 +104,94 @@ func (w *BaseWord) SetLocation(location *CodeLocation) {
 	w.location = location
 }
 
-func (w *BaseWord) AddErrorHandler(handler WordErrorHandler) {
-	w.errorHandlers = append(w.errorHandlers, handler)
+// AddErrorHandler adds a handler with priority 0 that applies in every interpreter
+func (w *BaseWord) AddErrorHandler(handler WordErrorHandler) ErrorHandlerHandle {
+	return w.AddErrorHandlerWithOptions(handler, ErrorHandlerOptions{})
 }
 
-func (w *BaseWord) RemoveErrorHandler(handler WordErrorHandler) {
-	// In Go, we can't directly compare function pointers for equality
-	// This is a limitation - we'll need to use a different approach
-	// For now, this is a no-op. Users should use ClearErrorHandlers instead.
-	// TODO: Consider using a handle/ID system for removable handlers
+// AddErrorHandlerWithOptions adds a handler with the given priority and scope
+func (w *BaseWord) AddErrorHandlerWithOptions(handler WordErrorHandler, options ErrorHandlerOptions) ErrorHandlerHandle {
+	entry := errorHandlerEntry{
+		handle:  ErrorHandlerHandle(atomic.AddUint64(&lastErrorHandlerHandle, 1)),
+		handler: handler,
+		options: options,
+	}
+
+	w.handlersMu.Lock()
+	defer w.handlersMu.Unlock()
+
+	// Keep handlers sorted by priority, preserving insertion order within a priority
+	pos := len(w.errorHandlers)
+	for j, e := range w.errorHandlers {
+		if e.options.Priority < options.Priority {
+			pos = j
+			break
+		}
+	}
+	handlers := make([]errorHandlerEntry, 0, len(w.errorHandlers)+1)
+	handlers = append(handlers, w.errorHandlers[:pos]...)
+	handlers = append(handlers, entry)
+	handlers = append(handlers, w.errorHandlers[pos:]...)
+	w.errorHandlers = handlers
+	return entry.handle
+}
+
+// RemoveErrorHandler removes the handler identified by handle
+// Returns false if the handler is not attached to this word.
+func (w *BaseWord) RemoveErrorHandler(handle ErrorHandlerHandle) bool {
+	w.handlersMu.Lock()
+	defer w.handlersMu.Unlock()
+
+	for j, e := range w.errorHandlers {
+		if e.handle == handle {
+			handlers := make([]errorHandlerEntry, 0, len(w.errorHandlers)-1)
+			handlers = append(handlers, w.errorHandlers[:j]...)
+			handlers = append(handlers, w.errorHandlers[j+1:]...)
+			w.errorHandlers = handlers
+			return true
+		}
+	}
+	return false
 }
 
 func (w *BaseWord) ClearErrorHandlers() {
-	w.errorHandlers = make([]WordErrorHandler, 0)
+	w.handlersMu.Lock()
+	defer w.handlersMu.Unlock()
+	w.errorHandlers = make([]errorHandlerEntry, 0)
 }
 
+// GetErrorHandlers returns the word's handlers in the order they are tried
 func (w *BaseWord) GetErrorHandlers() []WordErrorHandler {
-	// Return a copy
+	w.handlersMu.RLock()
+	defer w.handlersMu.RUnlock()
+
 	result := make([]WordErrorHandler, len(w.errorHandlers))
-	copy(result, w.errorHandlers)
+	for j, e := range w.errorHandlers {
+		result[j] = e.handler
+	}
 	return result
 }
 
-// TryErrorHandlers tries error handlers in order
+// TryErrorHandlers tries error handlers in priority order
+// Handlers scoped to another interpreter are skipped.
 // Returns nil if error was handled, otherwise returns error
 func (w *BaseWord) TryErrorHandlers(err error, word Word, interp *Interpreter) error {
-	// Check if error is IntentionalStopError - if so, bypass handlers
-	if _, ok := err.(*IntentionalStopError); ok {
+	// IntentionalStopError, cancellation and resource limits bypass handlers
+	if bypassesErrorHandlers(err) {
 		return err
 	}
 
-	for _, handler := range w.errorHandlers {
-		handlerErr := handler(err, word, interp)
+	// The slice is replaced, never modified, so handlers may add or remove
+	// handlers while it is being iterated
+	w.handlersMu.RLock()
+	handlers := w.errorHandlers
+	w.handlersMu.RUnlock()
+
+	for _, e := range handlers {
+		if e.options.Interp != nil && e.options.Interp != interp {
+			continue
+		}
+		handlerErr := e.handler(err, word, interp)
 		if handlerErr == nil {
 			// Handler succeeded, error is handled
 			return nil
@@ -142,14 +230,41 @@ func NewPushValueWord(name string, value interface{}) *PushValueWord {
 }
 
 func (w *PushValueWord) Execute(interp *Interpreter) error {
+	if variable, ok := w.value.(*Variable); ok && interp.variableCopies != nil {
+		interp.StackPush(interp.resolveVariable(variable))
+		return nil
+	}
 	interp.StackPush(w.value)
 	return nil
 }
 
+// stringLiteralWord - Word that pushes the string of a string literal
+// The interpreter remembers the literal's location with the pushed string,
+// so code run from the string reports locations within the literal.
+type stringLiteralWord struct {
+	*BaseWord
+	value    string
+	location *CodeLocation
+}
+
+func newStringLiteralWord(value string, location *CodeLocation) *stringLiteralWord {
+	return &stringLiteralWord{
+		BaseWord: NewBaseWord("<string>"),
+		value:    value,
+		location: location,
+	}
+}
+
+func (w *stringLiteralWord) Execute(interp *Interpreter) error {
+	interp.pushLiteral(w.value, w.location)
+	return nil
+}
+
 // ModuleWord - Word that wraps a function with error handler support
 type ModuleWord struct {
 	*BaseWord
 	handler func(*Interpreter) error
+	options OptionSchema
 }
 
 // NewModuleWord creates a new ModuleWord
@@ -160,8 +275,27 @@ func NewModuleWord(name string, handler func(*Interpreter) error) *ModuleWord {
 	}
 }
 
+// NewModuleWordWithOptions creates a ModuleWord that accepts the options in
+// schema; see OptionSchema
+func NewModuleWordWithOptions(name string, schema OptionSchema, handler func(*Interpreter, *WordOptions) error) *ModuleWord {
+	word := NewModuleWord(name, func(interp *Interpreter) error {
+		opts, err := schema.popOptions(interp, name)
+		if err != nil {
+			return err
+		}
+		return handler(interp, opts)
+	})
+	word.options = schema
+	return word
+}
+
+// OptionSchema returns the options the word accepts, or nil
+func (w *ModuleWord) OptionSchema() OptionSchema {
+	return w.options
+}
+
 func (w *ModuleWord) Execute(interp *Interpreter) error {
-	err := w.handler(interp)
+	err := w.callHandler(interp)
 	if err != nil {
 		// Try error handlers
 		if handledErr := w.TryErrorHandlers(err, w, interp); handledErr == nil {
@@ -172,12 +306,32 @@ func (w *ModuleWord) Execute(interp *Interpreter) error {
 	return nil
 }
 
+// callHandler runs the handler, converting panics into errors so that
+// error handlers see them too
+func (w *ModuleWord) callHandler(interp *Interpreter) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicToError(r, w, interp.CurrentLocation())
+		}
+	}()
+	return w.handler(interp)
+}
+
 // DefinitionWord - Word defined by a sequence of other words
 type DefinitionWord struct {
 	*BaseWord
-	words []Word
+	words     []Word
+	locations []*CodeLocation // where each word appears in the definition
+	source    string
+	memo      *ModuleMemoWord // set if the definition was made with "@:"
+	order     uint64          // orders definitions across modules; see lastDefinitionOrder
 }
 
+// lastDefinitionOrder is the order given to the most recent definition
+// made from source, so snapshots can replay definitions in any module in
+// the order they were made
+var lastDefinitionOrder uint64
+
 // NewDefinitionWord creates a new DefinitionWord
 func NewDefinitionWord(name string, words []Word) *DefinitionWord {
 	return &DefinitionWord{
@@ -187,8 +341,18 @@ func NewDefinitionWord(name string, words []Word) *DefinitionWord {
 }
 
 func (w *DefinitionWord) Execute(interp *Interpreter) error {
-	for _, word := range w.words {
-		err := word.Execute(interp)
+	if err := interp.enterCall(w.location); err != nil {
+		return err
+	}
+	defer interp.exitCall()
+
+	for j, word := range w.words {
+		location := w.locationOf(j)
+		if err := interp.checkContext(location); err != nil {
+			return err
+		}
+
+		err := executeWord(word, interp, location)
 		if err != nil {
 			// Try error handlers
 			if handledErr := w.TryErrorHandlers(err, w, interp); handledErr == nil {
@@ -200,6 +364,110 @@ func (w *DefinitionWord) Execute(interp *Interpreter) error {
 	return nil
 }
 
+// addWord appends a word to the definition, recording where it appears
+// Locations are kept by the definition rather than the word, since the same
+// word object may appear in many definitions, in any number of interpreters.
+func (w *DefinitionWord) addWord(word Word, location *CodeLocation) {
+	w.words = append(w.words, word)
+	w.locations = append(w.locations, location)
+}
+
+// locationOf returns where the jth word appears in the definition
+func (w *DefinitionWord) locationOf(j int) *CodeLocation {
+	if j < len(w.locations) && w.locations[j] != nil {
+		return w.locations[j]
+	}
+	return w.words[j].GetLocation()
+}
+
 func (w *DefinitionWord) GetWords() []Word {
 	return w.words
 }
+
+// GetSource returns the Forthic source of the definition, from ":" (or "@:")
+// through ";", or "" if it was not compiled from source
+func (w *DefinitionWord) GetSource() string {
+	return w.source
+}
+
+// ============================================================================
+// Panic Recovery
+// ============================================================================
+
+// executeWord executes a word, converting any panic raised during execution
+// into a returned error
+//
+// Each execution counts against the interpreter's instruction budget, and the
+// stack depth limit is checked once the word completes. Errors gain a frame
+// in their Forthic stack trace. Profiling, execution logging and observers
+// also hook in here.
+func executeWord(word Word, interp *Interpreter, location *CodeLocation) (err error) {
+	if err := interp.countInstruction(location); err != nil {
+		return err
+	}
+	interp.poppedLiteral = nil
+
+	if interp.isProfiling {
+		p := interp.profile
+		p.enterWord(word)
+		defer p.exitWord()
+	}
+
+	if interp.isLogging {
+		before := interp.stackSnapshot()
+		defer func() {
+			interp.logWordExecution(word, location, before, err)
+		}()
+	}
+
+	if len(interp.observers) > 0 {
+		interp.notifyBeforeWord(word, location)
+		defer func() {
+			interp.notifyAfterWord(word, location, err)
+		}()
+	}
+
+	defer func() {
+		if err != nil {
+			err = AddStackFrame(err, StackFrame{Word: word.GetName(), Location: location})
+		}
+	}()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicToError(r, word, location)
+		}
+	}()
+
+	if err := word.Execute(interp); err != nil {
+		return err
+	}
+	return interp.checkStackDepth(location)
+}
+
+// panicToError converts a recovered panic value into an error
+//
+// StackUnderflowErrors keep their type (gaining a location if they lack one).
+// Any other panic becomes a WordExecutionError naming the word, if known.
+func panicToError(r interface{}, word Word, location *CodeLocation) error {
+	if underflow, ok := r.(*StackUnderflowError); ok {
+		if underflow.Location == nil {
+			underflow.Location = location
+		}
+		if underflow.Word == "" && word != nil {
+			underflow.Word = word.GetName()
+		}
+		return underflow
+	}
+
+	cause, ok := r.(error)
+	if !ok {
+		cause = fmt.Errorf("%v", r)
+	}
+
+	name := "<unknown>"
+	if word != nil {
+		name = word.GetName()
+	}
+	return NewWordExecutionError(name, cause).WithLocation(location)
+}
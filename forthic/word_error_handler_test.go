@@ -1,7 +1,9 @@
 package forthic
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"testing"
 )
 
@@ -197,6 +199,33 @@ func TestWordErrorHandler_IntentionalStopErrorBypassesHandlers(t *testing.T) {
 	}
 }
 
+func TestWordErrorHandler_WrappedStopsBypassHandlers(t *testing.T) {
+	interp := NewInterpreter()
+	wrapped := []error{
+		fmt.Errorf("stopping: %w", NewIntentionalStopError("Intentional stop")),
+		NewWordExecutionError("INNER", NewResourceLimitError("MaxInstructions", 10)),
+		fmt.Errorf("waiting: %w", NewCancelledError(context.Canceled)),
+	}
+
+	for _, stopErr := range wrapped {
+		handlerCalled := false
+		word := NewModuleWord("STOPPING-WORD", func(interp *Interpreter) error {
+			return stopErr
+		})
+		word.AddErrorHandler(func(err error, w Word, i *Interpreter) error {
+			handlerCalled = true
+			return nil
+		})
+
+		if err := word.Execute(interp); err == nil {
+			t.Errorf("Expected %v to propagate", stopErr)
+		}
+		if handlerCalled {
+			t.Errorf("Handler should not be called for %v", stopErr)
+		}
+	}
+}
+
 func TestWordErrorHandler_AddModuleWordCreatesWordWithHandlerSupport(t *testing.T) {
 	interp := NewInterpreter()
 	module := NewModule("test-module", "")
@@ -252,3 +281,198 @@ func TestWordErrorHandler_HandlerReceivesWordAndInterpreter(t *testing.T) {
 		t.Error("Handler did not receive correct interpreter")
 	}
 }
+
+func TestWordErrorHandler_RemoveHandlerByHandle(t *testing.T) {
+	interp := NewInterpreter()
+	calls := []string{}
+
+	word := NewModuleWord("FAILING-WORD", func(interp *Interpreter) error {
+		return errors.New("Test error")
+	})
+
+	first := word.AddErrorHandler(func(err error, w Word, i *Interpreter) error {
+		calls = append(calls, "first")
+		return errors.New("not handled")
+	})
+	word.AddErrorHandler(func(err error, w Word, i *Interpreter) error {
+		calls = append(calls, "second")
+		return errors.New("not handled")
+	})
+
+	if !word.RemoveErrorHandler(first) {
+		t.Error("Expected RemoveErrorHandler to report removal")
+	}
+	if word.RemoveErrorHandler(first) {
+		t.Error("Expected second RemoveErrorHandler to report nothing removed")
+	}
+
+	_ = word.Execute(interp)
+
+	if len(calls) != 1 || calls[0] != "second" {
+		t.Errorf("Expected only second handler to be called, got %v", calls)
+	}
+}
+
+func TestWordErrorHandler_HandlesAreUniqueAcrossWords(t *testing.T) {
+	word1 := NewBaseWord("ONE")
+	word2 := NewBaseWord("TWO")
+	handler := func(err error, word Word, interp *Interpreter) error {
+		return nil
+	}
+
+	handle1 := word1.AddErrorHandler(handler)
+	handle2 := word2.AddErrorHandler(handler)
+
+	if handle1 == handle2 {
+		t.Error("Expected distinct handles")
+	}
+	if word2.RemoveErrorHandler(handle1) {
+		t.Error("Expected handle from another word not to be removed")
+	}
+	if len(word2.GetErrorHandlers()) != 1 {
+		t.Errorf("Expected 1 handler, got %d", len(word2.GetErrorHandlers()))
+	}
+}
+
+func TestWordErrorHandler_PriorityOrder(t *testing.T) {
+	interp := NewInterpreter()
+	callOrder := []string{}
+
+	word := NewModuleWord("FAILING-WORD", func(interp *Interpreter) error {
+		return errors.New("Test error")
+	})
+
+	record := func(name string) WordErrorHandler {
+		return func(err error, w Word, i *Interpreter) error {
+			callOrder = append(callOrder, name)
+			return errors.New("not handled")
+		}
+	}
+	word.AddErrorHandler(record("default-1"))
+	word.AddErrorHandlerWithOptions(record("high"), ErrorHandlerOptions{Priority: 10})
+	word.AddErrorHandlerWithOptions(record("low"), ErrorHandlerOptions{Priority: -1})
+	word.AddErrorHandler(record("default-2"))
+
+	_ = word.Execute(interp)
+
+	expected := []string{"high", "default-1", "default-2", "low"}
+	if len(callOrder) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, callOrder)
+	}
+	for j := range expected {
+		if callOrder[j] != expected[j] {
+			t.Errorf("Expected %v, got %v", expected, callOrder)
+			break
+		}
+	}
+}
+
+func TestWordErrorHandler_ScopedToInterpreter(t *testing.T) {
+	interp := NewInterpreter()
+	other := NewInterpreter()
+
+	word := NewModuleWord("FAILING-WORD", func(interp *Interpreter) error {
+		return errors.New("Test error")
+	})
+	word.AddErrorHandlerWithOptions(func(err error, w Word, i *Interpreter) error {
+		return nil
+	}, ErrorHandlerOptions{Interp: interp})
+
+	if err := word.Execute(interp); err != nil {
+		t.Errorf("Expected error to be handled in scoped interpreter, got %v", err)
+	}
+	if err := word.Execute(other); err == nil {
+		t.Error("Expected error to propagate in other interpreter")
+	}
+}
+
+func TestWordErrorHandler_RunWithErrorHandlers(t *testing.T) {
+	interp := NewInterpreter()
+	module := NewModule("test-module", "")
+	module.AddModuleWord("FAILING", func(interp *Interpreter) error {
+		return errors.New("Test error")
+	})
+	interp.ImportModule(module, "")
+
+	word, err := interp.FindWord("FAILING")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	err = interp.RunWithErrorHandlers("FAILING", ScopedErrorHandler{
+		Word: word,
+		Handler: func(err error, w Word, i *Interpreter) error {
+			i.StackPush("fallback")
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected error to be handled, got %v", err)
+	}
+	if interp.StackPop() != "fallback" {
+		t.Error("Expected handler to push fallback")
+	}
+
+	if len(word.GetErrorHandlers()) != 0 {
+		t.Errorf("Expected handler to be removed after run, got %d", len(word.GetErrorHandlers()))
+	}
+	if err := interp.Run("FAILING"); err == nil {
+		t.Error("Expected error once scoped handler is removed")
+	}
+}
+
+func TestWordErrorHandler_PrefixedImportUsesTargetHandlers(t *testing.T) {
+	interp := NewInterpreter()
+	module := NewModule("test-module", "")
+	module.AddModuleWord("FAILING", func(interp *Interpreter) error {
+		return errors.New("Test error")
+	})
+
+	target := module.FindWord("FAILING")
+	target.AddErrorHandler(func(err error, w Word, i *Interpreter) error {
+		i.StackPush("target")
+		return nil
+	})
+	interp.ImportModule(module, "m")
+
+	if err := interp.Run("m.FAILING"); err != nil {
+		t.Fatalf("Expected target handler to handle error, got %v", err)
+	}
+	if interp.StackPop() != "target" {
+		t.Error("Expected target handler to run")
+	}
+}
+
+func TestWordErrorHandler_ExecuteWordHandlers(t *testing.T) {
+	interp := NewInterpreter()
+	module := NewModule("test-module", "")
+	module.AddModuleWord("FAILING", func(interp *Interpreter) error {
+		return errors.New("Test error")
+	})
+	interp.ImportModule(module, "m")
+
+	word, err := interp.FindWord("m.FAILING")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := word.(*ExecuteWord); !ok {
+		t.Fatalf("Expected ExecuteWord, got %T", word)
+	}
+
+	handle := word.AddErrorHandler(func(err error, w Word, i *Interpreter) error {
+		i.StackPush("prefixed")
+		return nil
+	})
+
+	if err := interp.Run("m.FAILING"); err != nil {
+		t.Fatalf("Expected ExecuteWord handler to handle error, got %v", err)
+	}
+	if interp.StackPop() != "prefixed" {
+		t.Error("Expected ExecuteWord handler to run")
+	}
+
+	word.RemoveErrorHandler(handle)
+	if err := interp.Run("m.FAILING"); err == nil {
+		t.Error("Expected error once handler is removed")
+	}
+}